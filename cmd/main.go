@@ -2,11 +2,13 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"golisp/pkg/interpreter"
 	"golisp/pkg/parser"
 	"golisp/pkg/scanner"
 	"os"
+	"strconv"
 	"strings"
 )
 
@@ -35,13 +37,17 @@ func runFile(path string) error {
 		return err
 	}
 
-	err = run(strings.ToLower(string(bytes)))
-
-	if scanner.HadError() {
-		os.Exit(65)
-	}
+	source := string(bytes)
+	err = run(source, path)
 	if err != nil {
-		fmt.Println(err)
+		exitOnRequest(err)
+
+		var diagnostics scanner.Diagnostics
+		if errors.As(err, &diagnostics) {
+			fmt.Fprintln(os.Stderr, interpreter.FormatError(err, source))
+			os.Exit(65)
+		}
+		fmt.Println(interpreter.FormatError(err, source))
 		os.Exit(70)
 	}
 
@@ -50,20 +56,26 @@ func runFile(path string) error {
 
 func runPrompt() {
 	theScanner := bufio.NewScanner(os.Stdin)
+	i.Debugger = func(condition interpreter.Value, restarts []interpreter.Restart) (int, string, bool) {
+		return chooseRestart(theScanner, condition, restarts)
+	}
 
-	for {
+	for lines := 1; ; lines++ {
 		fmt.Print(">>> ")
 		if !theScanner.Scan() {
 			break
 		}
 
 		line := theScanner.Text()
-		err := run(strings.ToLower(line))
+		if strings.TrimSpace(line) == "exit" { // shorthand for (exit) that only exists in the REPL
+			break
+		}
+
+		err := run(line, fmt.Sprintf("<repl:%d>", lines)) // each line is named apart, as it is formatted on its own
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(70)
+			exitOnRequest(err)
+			fmt.Println(interpreter.FormatError(err, line))
 		}
-		scanner.SetErrorFlag(false)
 	}
 
 	if theScanner.Err() != nil {
@@ -71,31 +83,57 @@ func runPrompt() {
 	}
 }
 
-func run(source string) error {
+// chooseRestart lets the user pick a restart for an error that was not handled, with a final choice of
+// aborting back to the prompt. Arguments for the restart are typed after its number, as in "0 42"
+func chooseRestart(theScanner *bufio.Scanner, condition interpreter.Value, restarts []interpreter.Restart) (int, string, bool) {
+	fmt.Println("Unhandled condition:", condition)
+	fmt.Println("Available restarts:")
+	for j, restart := range restarts {
+		fmt.Printf("  %d: %s", j, restart.Name)
+		if len(restart.Params) > 0 {
+			fmt.Printf(" (%s)", strings.Join(restart.Params, " "))
+		}
+		fmt.Println()
+	}
+	fmt.Printf("  %d: abort\n", len(restarts))
+
+	for {
+		fmt.Print("restart> ")
+		if !theScanner.Scan() {
+			return 0, "", false
+		}
+		choice, arguments, _ := strings.Cut(strings.TrimSpace(theScanner.Text()), " ")
+		number, err := strconv.Atoi(choice)
+		if err != nil || number < 0 || number > len(restarts) {
+			fmt.Println("Choose a restart by its number")
+			continue
+		}
+		return number, arguments, number < len(restarts)
+	}
+}
+
+// exitOnRequest ends the process with the status code passed to exit, if err came from a call to it
+func exitOnRequest(err error) {
+	var exitErr *interpreter.ExitError
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.Code)
+	}
+}
+
+// run evaluates source, with file naming where it came from in error messages
+func run(source string, file string) error {
 	thisScanner := scanner.NewScanner(source)
+	thisScanner.File = file
+	thisScanner.CaseSensitive = i.CaseSensitive
 	tokens := thisScanner.ScanTokens()
-
-	thisParser := parser.NewParser(tokens)
-	expr, err := thisParser.Parse()
-	if err != nil {
-		fmt.Println(err)
-		return err
+	if thisScanner.HadError() {
+		return thisScanner.Diagnostics
 	}
 
-	// fmt.Println("Expression: " + expr.String())
-	// fmt.Println()
+	thisParser := parser.NewParser(tokens)
 
 	// fmt.Println("Interpreting...")
 	// fmt.Println()
 
-	err = i.Interpret(expr)
-	// if output != nil {
-	// 	fmt.Println(output)
-	// }
-	if err != nil {
-		fmt.Println(err)
-		os.Exit(70)
-		return err
-	}
-	return nil
+	return i.Run(&thisParser)
 }
@@ -0,0 +1,42 @@
+// Package golisp runs yisp source code from Go programs. Every scan, parse and runtime error is
+// returned as a value, and a script calling exit returns an *ExitError rather than ending the process
+package golisp
+
+import (
+	"io"
+
+	"golisp/pkg/interpreter"
+)
+
+// Value is any value the language can work with
+type Value = interpreter.Value
+
+// Interpreter holds the global environment that successive evaluations share
+type Interpreter = interpreter.Interpreter
+
+// ExitError is returned when a script calls exit, carrying the status code it asked for
+type ExitError = interpreter.ExitError
+
+// NewInterpreter returns an interpreter with only the builtins defined, for running several
+// pieces of source code against the same global environment
+func NewInterpreter() *Interpreter {
+	i := interpreter.NewInterpreter()
+	return &i
+}
+
+// EvalString evaluates source code in a fresh interpreter and returns the value of its last expression
+func EvalString(source string) (Value, error) {
+	return NewInterpreter().EvalString(source)
+}
+
+// EvalReader evaluates the source code read from r in a fresh interpreter and returns the value of
+// its last expression
+func EvalReader(r io.Reader) (Value, error) {
+	return NewInterpreter().EvalReader(r)
+}
+
+// FormatError returns the message of an error from evaluating source, followed by the line of source
+// it occurred on with the offending code underlined
+func FormatError(err error, source string) string {
+	return interpreter.FormatError(err, source)
+}
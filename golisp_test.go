@@ -0,0 +1,112 @@
+package golisp
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"golisp/pkg/interpreter"
+	"golisp/pkg/scanner"
+)
+
+func TestEvalString(t *testing.T) {
+	value, err := EvalString("(define square (x) (* x x)) (square 12)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != int64(144) {
+		t.Errorf("got %v, want 144", value)
+	}
+}
+
+func TestEvalStringReturnsDiagnostics(t *testing.T) {
+	tests := []struct {
+		source string
+		phase  string
+	}{
+		{`"unterminated`, scanner.ScanPhase},
+		{"(define broken (x)", scanner.ParsePhase},
+	}
+
+	for _, test := range tests {
+		_, err := EvalString(test.source)
+		var diagnostics scanner.Diagnostics
+		if !errors.As(err, &diagnostics) || len(diagnostics) == 0 {
+			t.Errorf("%s: got error %v, want scanner.Diagnostics", test.source, err)
+			continue
+		}
+		if diagnostics[0].Phase != test.phase {
+			t.Errorf("%s: got phase %v, want %v", test.source, diagnostics[0].Phase, test.phase)
+		}
+	}
+}
+
+func TestEvalStringReturnsExitError(t *testing.T) {
+	_, err := EvalString("(define f () (exit 3)) (f) (exit 4)")
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("got error %v, want *ExitError", err)
+	}
+	if exitErr.Code != 3 {
+		t.Errorf("got exit code %d, want 3", exitErr.Code)
+	}
+}
+
+func TestEvalStringReturnsRuntimeErrors(t *testing.T) {
+	tests := []string{
+		"(car 1)",
+		"(define f (n) (+ 1 (f n))) (f 1)",
+	}
+
+	for _, source := range tests {
+		_, err := EvalString(source)
+		var runtimeErr *interpreter.RuntimeError
+		if !errors.As(err, &runtimeErr) {
+			t.Errorf("%s: got error %v, want *interpreter.RuntimeError", source, err)
+		}
+	}
+}
+
+func TestEvalStringReportsRunawayMacros(t *testing.T) {
+	_, err := EvalString("(defmacro m () '(m)) (m)")
+	var diagnostics scanner.Diagnostics
+	if !errors.As(err, &diagnostics) {
+		t.Errorf("got error %v, want scanner.Diagnostics", err)
+	}
+}
+
+// TestEvalStringInParallel runs interpreters in parallel goroutines, some of them failing, to check that
+// none of them shares error state with another. Run it with go test -race
+func TestEvalStringInParallel(t *testing.T) {
+	var wait sync.WaitGroup
+	for j := 0; j < 32; j++ {
+		wait.Add(1)
+		go func(n int) {
+			defer wait.Done()
+			for k := 0; k < 20; k++ {
+				switch (n + k) % 3 {
+				case 0:
+					source := fmt.Sprintf("(define f (x) (* x %d)) (f 2)", n)
+					value, err := EvalString(source)
+					if err != nil || value != int64(2*n) {
+						t.Errorf("%s: got %v, %v, want %d", source, value, err, 2*n)
+					}
+				case 1:
+					source := fmt.Sprintf("(1 %d . )\n(define broken (x)", n)
+					_, err := EvalString(source)
+					var diagnostics scanner.Diagnostics
+					if !errors.As(err, &diagnostics) || len(diagnostics) != 2 {
+						t.Errorf("%q: got error %v, want its 2 syntax errors", source, err)
+					}
+				case 2:
+					value, err := EvalString(fmt.Sprintf(`(try (car %d) (catch (e) (error-kind e)))`, n))
+					if err != nil || fmt.Sprint(value) != "runtime-error" {
+						t.Errorf("caught %v, %v, want runtime-error", value, err)
+					}
+				}
+			}
+		}(j)
+	}
+	wait.Wait()
+}
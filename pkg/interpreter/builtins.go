@@ -0,0 +1,371 @@
+package interpreter
+
+import (
+	"strings"
+
+	"golisp/pkg/scanner"
+)
+
+// Builtin is a function implemented in Go. Builtins are bound in the global environment, so they can
+// be called, passed to other functions and stored in variables just like functions defined in Lisp
+type Builtin struct {
+	Name  string
+	arity int // -1 for variadic builtins, which check their own arguments
+	Fn    func(i *Interpreter, arguments []interface{}) (interface{}, error)
+}
+
+// String returns a string representation of the builtin for debugging purposes
+func (b Builtin) String() string {
+	return "<native fn " + b.Name + ">"
+}
+
+// Arity is the number of arguments the builtin takes, or -1 if it takes any number of them
+func (b Builtin) Arity() int {
+	return b.arity
+}
+
+// Call runs the Go implementation of the builtin
+func (b Builtin) Call(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	return b.Fn(i, arguments)
+}
+
+// builtins are bound in the global environment of every interpreter. Predicates and comparisons
+// return true or nil, the language's true and false values
+var builtins = []Builtin{
+	{Name: "cons", arity: 2, Fn: cons},
+	{Name: "car", arity: 1, Fn: car},
+	{Name: "cdr", arity: 1, Fn: cdr},
+	{Name: "list", arity: -1, Fn: list},
+	{Name: "number?", arity: 1, Fn: numberQ},
+	{Name: "list?", arity: 1, Fn: listQ},
+	{Name: "nil?", arity: 1, Fn: nilQ},
+	symbolQBuiltin,
+	{Name: "and?", arity: 2, Fn: andQ},
+	{Name: "or?", arity: 2, Fn: orQ},
+	{Name: "not?", arity: 1, Fn: notQ},
+	{Name: "exit", arity: -1, Fn: exit},
+	{Name: "error", arity: -1, Fn: raiseError},
+	{Name: "error?", arity: 1, Fn: errorQ},
+	{Name: "error-message", arity: 1, Fn: errorMessage},
+	{Name: "error-kind", arity: 1, Fn: errorKind},
+	{Name: "error-data", arity: 1, Fn: errorData},
+	{Name: "make-condition", arity: -1, Fn: makeConditionBuiltin},
+	{Name: "signal", arity: -1, Fn: signalBuiltin},
+	{Name: "invoke-restart", arity: -1, Fn: invokeRestart},
+	{Name: "compute-restarts", arity: 0, Fn: computeRestarts},
+	{Name: "condition?", arity: 1, Fn: conditionQ},
+	{Name: "condition-message", arity: 1, Fn: conditionMessage},
+	{Name: "condition-kind", arity: 1, Fn: conditionKind},
+	{Name: "condition-data", arity: 1, Fn: conditionData},
+	{Name: "call-with-current-continuation", arity: 1, Fn: callCC},
+	{Name: "call/cc", arity: 1, Fn: callCC},
+	{Name: "dynamic-wind", arity: 3, Fn: dynamicWind},
+	{Name: "macroexpand", arity: 1, Fn: macroexpand},
+	{Name: "macroexpand-1", arity: 1, Fn: macroexpand1},
+}
+
+// operators are the builtins behind the operator tokens. They are applied directly when an operator
+// heads a list, and are bound in the global environment under their lexemes as well. All of them are
+// variadic: arithmetic folds from left to right, and comparisons hold between every neighbouring pair
+var operators = map[scanner.TokenType]Builtin{
+	scanner.PLUS:          {Name: "+", arity: -1, Fn: add},
+	scanner.MINUS:         {Name: "-", arity: -1, Fn: subtract},
+	scanner.STAR:          {Name: "*", arity: -1, Fn: multiply},
+	scanner.SLASH:         {Name: "/", arity: -1, Fn: divide},
+	scanner.EQUAL:         {Name: "=", arity: -1, Fn: equal},
+	scanner.BANG_EQUAL:    {Name: "!=", arity: -1, Fn: notEqual},
+	scanner.LESS:          {Name: "<", arity: -1, Fn: less},
+	scanner.LESS_EQUAL:    {Name: "<=", arity: -1, Fn: lessEqual},
+	scanner.GREATER:       {Name: ">", arity: -1, Fn: greater},
+	scanner.GREATER_EQUAL: {Name: ">=", arity: -1, Fn: greaterEqual},
+}
+
+// defineBuiltins binds every builtin and operator in the passed environment
+func defineBuiltins(env *Environment) {
+	for _, builtin := range builtins {
+		env.define(builtin.Name, builtin)
+	}
+	for _, operator := range operators {
+		env.define(operator.Name, operator)
+	}
+}
+
+// withToken attaches the token of the call site to a RuntimeError raised by a builtin,
+// since builtins have no token of their own to report
+func withToken(err error, token scanner.Token) error {
+	if runtimeErr, ok := err.(*RuntimeError); ok && runtimeErr.Token.Line == 0 {
+		runtimeErr.Token = token
+	}
+	return err
+}
+
+// cons builds a new pair out of its two arguments, the second usually being a list
+func cons(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	return NewPair(arguments[0], arguments[1]), nil
+}
+
+// car returns the first half of a pair, which for a list is its first element
+func car(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	if arguments[0] == nil { // the car of the empty list is nil
+		return nil, nil
+	}
+	pair, ok := arguments[0].(*Pair)
+	if !ok {
+		return nil, &RuntimeError{Message: "CAR operation must have a list as the first operand"}
+	}
+	return pair.Car, nil
+}
+
+// cdr returns the second half of a pair, which for a list is everything but the first element
+func cdr(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	if arguments[0] == nil { // the cdr of the empty list is nil
+		return nil, nil
+	}
+	pair, ok := arguments[0].(*Pair)
+	if !ok {
+		return nil, &RuntimeError{Message: "CDR operation must have a list as the first operand"}
+	}
+	return pair.Cdr, nil
+}
+
+// list returns a proper list of its arguments
+func list(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	return NewList(arguments...), nil
+}
+
+// numberQ returns true if the argument is of type 'number'
+func numberQ(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	return truthValue(isNumber(arguments[0])), nil
+}
+
+// listQ returns true if the argument is a list
+func listQ(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	return truthValue(isList(arguments[0])), nil
+}
+
+// nilQ returns true if the argument is nil
+func nilQ(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	return truthValue(arguments[0] == nil), nil
+}
+
+// symbolQBuiltin is what symbol? evaluates to when it is passed around. At the head of a list, symbol? is
+// also true of a symbol written as its argument, without evaluating it
+var symbolQBuiltin = Builtin{Name: "symbol?", arity: 1, Fn: symbolQ}
+
+// symbolQ returns true if the argument is a symbol
+func symbolQ(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	_, ok := arguments[0].(Symbol)
+	return truthValue(ok), nil
+}
+
+// andQ is the logical AND operation
+func andQ(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	return truthValue(isTruthy(arguments[0]) && isTruthy(arguments[1])), nil
+}
+
+// orQ is the logical OR operation
+func orQ(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	return truthValue(isTruthy(arguments[0]) || isTruthy(arguments[1])), nil
+}
+
+// notQ is the logical NOT operation
+func notQ(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	return truthValue(!isTruthy(arguments[0])), nil
+}
+
+// exit raises an ExitError carrying the optional status code, which defaults to 0
+func exit(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	if len(arguments) > 1 {
+		return nil, &RuntimeError{Message: "EXIT takes at most 1 argument"}
+	}
+	code := 0
+	if len(arguments) == 1 {
+		status, ok := arguments[0].(int64)
+		if !ok {
+			return nil, &RuntimeError{Message: "EXIT status must be an integer"}
+		}
+		code = int(status)
+	}
+	return nil, &ExitError{Code: code}
+}
+
+// raiseError raises an error with a message and any number of data values, as in (error "not found" key).
+// A symbol before the message sets the kind of the error, and passing a caught error raises it again.
+// Like any runtime error, it is signalled as a condition, so handlers can invoke a restart before anything unwinds
+func raiseError(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	var value *LispError
+	if len(arguments) == 1 {
+		value, _ = arguments[0].(*LispError)
+	}
+	if value == nil {
+		c, err := makeCondition("ERROR", "error", arguments)
+		if err != nil {
+			return nil, err
+		}
+		value = &LispError{Condition: c}
+	}
+
+	// The data are shown after the message when the error is not caught
+	text := value.Message
+	for rest := value.Data; rest != nil; rest = rest.(*Pair).Cdr {
+		text += " " + stringify(rest.(*Pair).Car)
+	}
+	return nil, &RuntimeError{Message: text, Value: value}
+}
+
+// errorQ returns true if the argument is an error caught by try
+func errorQ(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	_, ok := arguments[0].(*LispError)
+	return truthValue(ok), nil
+}
+
+// errorMessage returns the message of a caught error
+func errorMessage(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	caught, ok := arguments[0].(*LispError)
+	if !ok {
+		return nil, &RuntimeError{Message: "ERROR-MESSAGE operation must have an error as the first operand"}
+	}
+	return caught.Message, nil
+}
+
+// errorKind returns the kind of a caught error as a symbol
+func errorKind(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	caught, ok := arguments[0].(*LispError)
+	if !ok {
+		return nil, &RuntimeError{Message: "ERROR-KIND operation must have an error as the first operand"}
+	}
+	return caught.Kind, nil
+}
+
+// errorData returns the list of data values a caught error was raised with
+func errorData(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	caught, ok := arguments[0].(*LispError)
+	if !ok {
+		return nil, &RuntimeError{Message: "ERROR-DATA operation must have an error as the first operand"}
+	}
+	return caught.Data, nil
+}
+
+// add sums numbers or concatenates strings. With no arguments it returns 0
+func add(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	if len(arguments) > 0 {
+		if _, ok := arguments[0].(string); ok {
+			var builder strings.Builder
+			for _, argument := range arguments {
+				str, ok := argument.(string)
+				if !ok {
+					return nil, &RuntimeError{Message: "Operators must be all numbers or all strings"}
+				}
+				builder.WriteString(str)
+			}
+			return builder.String(), nil
+		}
+	}
+	result, err := fold(addition.apply, int64(0), arguments)
+	if err != nil {
+		return nil, &RuntimeError{Message: "Operators must be all numbers or all strings"}
+	}
+	return result, nil
+}
+
+// subtract negates a single argument, and otherwise subtracts the rest of the arguments from the first
+func subtract(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	switch len(arguments) {
+	case 0:
+		return nil, &RuntimeError{Message: "- operation must have at least 1 operand"}
+	case 1:
+		return subtraction.apply(int64(0), arguments[0])
+	}
+	return fold(subtraction.apply, arguments[0], arguments[1:])
+}
+
+// multiply returns the product of its arguments, which is 1 with no arguments
+func multiply(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	return fold(multiplication.apply, int64(1), arguments)
+}
+
+// divide returns the reciprocal of a single argument, and otherwise divides the first argument by the rest
+func divide(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	switch len(arguments) {
+	case 0:
+		return nil, &RuntimeError{Message: "/ operation must have at least 1 operand"}
+	case 1:
+		return quotient(int64(1), arguments[0])
+	}
+	return fold(quotient, arguments[0], arguments[1:])
+}
+
+func equal(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	return chain(arguments, func(a, b interface{}) (bool, error) {
+		return isEqual(a, b), nil
+	})
+}
+
+// notEqual returns true if no two of its arguments are equal, rather than only neighbouring ones
+func notEqual(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	if len(arguments) == 0 {
+		return nil, &RuntimeError{Message: "Comparison must have at least 1 operand"}
+	}
+	for j := range arguments {
+		for k := j + 1; k < len(arguments); k++ {
+			if isEqual(arguments[j], arguments[k]) {
+				return nil, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+func less(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	return chain(arguments, ordered(func(comparison int) bool { return comparison < 0 }))
+}
+
+func lessEqual(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	return chain(arguments, ordered(func(comparison int) bool { return comparison <= 0 }))
+}
+
+func greater(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	return chain(arguments, ordered(func(comparison int) bool { return comparison > 0 }))
+}
+
+func greaterEqual(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	return chain(arguments, ordered(func(comparison int) bool { return comparison >= 0 }))
+}
+
+// fold applies op to the arguments from left to right starting with initial, so (- a b c) is (a - b) - c
+func fold(op func(a, b interface{}) (interface{}, error), initial interface{}, arguments []interface{}) (interface{}, error) {
+	result := initial
+	for _, argument := range arguments {
+		var err error
+		result, err = op(result, argument)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// chain returns true if test holds between every neighbouring pair of arguments, as in (< a b c)
+func chain(arguments []interface{}, test func(a, b interface{}) (bool, error)) (interface{}, error) {
+	if len(arguments) == 0 {
+		return nil, &RuntimeError{Message: "Comparison must have at least 1 operand"}
+	}
+	for j := 1; j < len(arguments); j++ {
+		holds, err := test(arguments[j-1], arguments[j])
+		if err != nil {
+			return nil, err
+		}
+		if !holds {
+			return nil, nil
+		}
+	}
+	return true, nil
+}
+
+// ordered turns a test of the result of compareNumbers into a test of two numbers, for chain
+func ordered(test func(comparison int) bool) func(a, b interface{}) (bool, error) {
+	return func(a, b interface{}) (bool, error) {
+		comparison, err := compareNumbers(a, b)
+		return test(comparison), err
+	}
+}
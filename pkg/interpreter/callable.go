@@ -4,6 +4,7 @@ import (
 	// "time"
 	// "fmt"
 	"golisp/pkg/parser"
+	"golisp/pkg/scanner"
 )
 
 // LispCallable  is the interface that LispFunction implements (unnecessary?)
@@ -30,13 +31,45 @@ func (l LispFunction) Arity() int {
 	return len(l.Declaration.Params)
 }
 
-// Call generates a new environment, defines each parameter as the passed arguments, then evaluates
+// Call generates a new environment, defines each parameter as the passed arguments, then evaluates.
+// The body is evaluated in tail position, so a call it ends in comes back as a tailCall and is run
+// by looping here rather than by recursing
 func (l LispFunction) Call(i *Interpreter, arguments []interface{}) (interface{}, error) {
-	env := NewEnvironmentWithEnclosing(*l.Closure)
+	return l.call(i, scanner.Token{}, arguments)
+}
 
-	for j, param := range l.Declaration.Params {
-		env.define(param.Lexeme, arguments[j])
+// call is Call for a function called from the source code at token, which is recorded on the call stack
+// for tracebacks. A tail call replaces the frame of the function it returns from, keeping its call site
+// since that is where the result ends up
+func (l LispFunction) call(i *Interpreter, token scanner.Token, arguments []interface{}) (interface{}, error) {
+	if err := i.pushFrame(Frame{Function: l.Declaration.Name.Lexeme, Call: token, Arguments: arguments}); err != nil {
+		return nil, err
 	}
+	defer i.popFrame()
+
+	for {
+		env := NewEnvironmentWithEnclosing(l.Closure)
+
+		for j, param := range l.Declaration.Params {
+			env.define(param.Lexeme, arguments[j])
+		}
+
+		result, err := i.evaluateFunction(l.Declaration.Body, env, true)
+		if err != nil {
+			return nil, i.traceback(err)
+		}
+
+		call, ok := result.(*tailCall)
+		if !ok {
+			return result, nil
+		}
+		l, arguments = call.function, call.arguments
+		i.frames[len(i.frames)-1] = Frame{Function: l.Declaration.Name.Lexeme, Call: token, Arguments: arguments}
+	}
+}
 
-	return i.evaluateFunction(l.Declaration.Body, env)
+// tailCall is returned in place of a value by a function call in tail position
+type tailCall struct {
+	function  LispFunction
+	arguments []interface{}
 }
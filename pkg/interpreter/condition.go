@@ -0,0 +1,277 @@
+package interpreter
+
+import (
+	"golisp/pkg/parser"
+	"golisp/pkg/scanner"
+)
+
+// Condition is a value describing a situation that Lisp code may want to respond to, like a missing
+// field. Signalling a condition calls the handlers bound for it without unwinding anything, so they can
+// decide how evaluation continues by invoking a restart. Every runtime error is a condition as well,
+// whether raised by the interpreter or with the error builtin, with handlers running before the error
+// unwinds towards a try
+type Condition struct {
+	Kind    Symbol
+	Message string
+	Data    interface{} // a list of any other values the condition was made with
+}
+
+// String returns a string representation of the condition for debugging purposes
+func (c *Condition) String() string {
+	return "<" + c.Kind.Name + ": " + c.Message + ">"
+}
+
+// asCondition returns the condition behind a condition or error value
+func asCondition(value interface{}) (*Condition, bool) {
+	switch c := value.(type) {
+	case *Condition:
+		return c, true
+	case *LispError:
+		return &c.Condition, true
+	}
+	return nil, false
+}
+
+// makeCondition builds a condition from arguments of the form 'kind "message" data..., where the kind
+// may be left out in favor of defaultKind. name is the operation reported in errors
+func makeCondition(name string, defaultKind string, arguments []interface{}) (Condition, error) {
+	kind := Symbol{Name: defaultKind}
+	if len(arguments) > 0 {
+		if symbol, ok := arguments[0].(Symbol); ok {
+			kind = symbol
+			arguments = arguments[1:]
+		}
+	}
+	if len(arguments) == 0 {
+		return Condition{}, &RuntimeError{Message: name + " must have a message"}
+	}
+	message, ok := arguments[0].(string)
+	if !ok {
+		return Condition{}, &RuntimeError{Message: name + " message must be a string"}
+	}
+	return Condition{Kind: kind, Message: message, Data: NewList(arguments[1:]...)}, nil
+}
+
+// handler is a function bound by handler-bind for conditions of one kind. The kind condition matches
+// every condition, and the kind error matches every error
+type handler struct {
+	kind     Symbol
+	function LispCallable
+}
+
+// handles reports whether the handler is for the passed condition or error value
+func (h handler) handles(value interface{}) bool {
+	if h.kind.Name == "condition" {
+		return true
+	}
+	if _, ok := value.(*LispError); ok && h.kind.Name == "error" {
+		return true
+	}
+	c, _ := asCondition(value)
+	return c.Kind.Name == h.kind.Name
+}
+
+// restartPoint is a restart-case that is being evaluated, whose restarts can be invoked
+type restartPoint struct {
+	restarts []parser.RestartClause
+}
+
+// restartTransfer unwinds evaluation from invoke-restart back to the restart-case that established the
+// restart. It is an error so that it travels up like one, but try never catches it
+type restartTransfer struct {
+	point     *restartPoint
+	restart   int
+	arguments []interface{}
+}
+
+func (r *restartTransfer) Error() string {
+	return "restart " + r.point.restarts[r.restart].Name.Lexeme + " invoked outside of its restart-case"
+}
+
+// Restart describes an available restart to a Debugger
+type Restart struct {
+	Name   string
+	Params []string
+}
+
+// signal calls the handlers for a condition, innermost first. A handler runs with only the handlers outside
+// of its own handler-bind in effect, and declines by returning, leaving the condition to the next handler.
+// If every handler declines an error that no try is going to catch, the Debugger is offered the available
+// restarts
+func (i *Interpreter) signal(value interface{}, isError bool) error {
+	for j := len(i.handlers) - 1; j >= 0; j-- {
+		for _, h := range i.handlers[j] {
+			if !h.handles(value) {
+				continue
+			}
+
+			outer := i.handlers
+			i.handlers = i.handlers[:j:j] // handler-binds in the handler must not overwrite outer ones
+			_, err := h.function.Call(i, []interface{}{value})
+			i.handlers = outer
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if isError && i.Debugger != nil && len(i.restarts) > 0 && i.catching == 0 {
+		return i.debug(value)
+	}
+	return nil
+}
+
+// raised signals a RuntimeError the first time it comes out of an expression, while the handlers and
+// restarts around the code that raised it are still in effect. Other errors, like the transfers made by
+// restarts and continuations, are passed through
+func (i *Interpreter) raised(err error) error {
+	runtimeErr, ok := err.(*RuntimeError)
+	if !ok || runtimeErr.signalled {
+		return err
+	}
+	runtimeErr.signalled = true
+	runtimeErr.Value = runtimeErr.value()
+	if signalErr := i.signal(runtimeErr.Value, true); signalErr != nil {
+		return signalErr
+	}
+	return err
+}
+
+// activeRestarts returns every restart that can be invoked, innermost first
+func (i *Interpreter) activeRestarts() []*restartTransfer {
+	var active []*restartTransfer
+	for j := len(i.restarts) - 1; j >= 0; j-- {
+		for k := range i.restarts[j].restarts {
+			active = append(active, &restartTransfer{point: i.restarts[j], restart: k})
+		}
+	}
+	return active
+}
+
+// debug lets the Debugger choose a restart for an error no handler took care of. The arguments for the
+// restart are given as source code, which is evaluated where the error was raised
+func (i *Interpreter) debug(value interface{}) error {
+	active := i.activeRestarts()
+	restarts := make([]Restart, len(active))
+	for j, transfer := range active {
+		clause := transfer.point.restarts[transfer.restart]
+		restarts[j] = Restart{Name: clause.Name.Lexeme}
+		for _, param := range clause.Params {
+			restarts[j].Params = append(restarts[j].Params, param.Lexeme)
+		}
+	}
+
+	choice, source, ok := i.Debugger(value, restarts)
+	if !ok || choice < 0 || choice >= len(active) {
+		return nil
+	}
+
+	thisScanner := scanner.NewScanner(source)
+	thisScanner.CaseSensitive = i.CaseSensitive
+	tokens := thisScanner.ScanTokens()
+	if thisScanner.HadError() {
+		return thisScanner.Diagnostics
+	}
+	thisParser := parser.NewParser(tokens)
+	thisParser.Macros = i
+	exprs, err := thisParser.Parse()
+	if err != nil {
+		return err
+	}
+
+	transfer := active[choice]
+	for _, expr := range exprs {
+		argument, err := i.evaluate(expr)
+		if err != nil {
+			return err
+		}
+		transfer.arguments = append(transfer.arguments, argument)
+	}
+	return transfer
+}
+
+// makeConditionBuiltin returns a condition made from its arguments, as in (make-condition 'missing-field "no name")
+func makeConditionBuiltin(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	c, err := makeCondition("MAKE-CONDITION", "condition", arguments)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// signalBuiltin signals a condition value, or one made from its arguments like make-condition, and returns
+// nil once every handler has declined it
+func signalBuiltin(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	if len(arguments) == 1 {
+		if _, ok := asCondition(arguments[0]); ok {
+			return nil, i.signal(arguments[0], false)
+		}
+	}
+	c, err := makeCondition("SIGNAL", "condition", arguments)
+	if err != nil {
+		return nil, err
+	}
+	return nil, i.signal(&c, false)
+}
+
+// invokeRestart transfers control to the innermost active restart with the passed name, handing it the
+// rest of the arguments
+func invokeRestart(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	if len(arguments) == 0 {
+		return nil, &RuntimeError{Message: "INVOKE-RESTART must have a restart name"}
+	}
+	name, ok := arguments[0].(Symbol)
+	if !ok {
+		return nil, &RuntimeError{Message: "INVOKE-RESTART must have a symbol as the first operand"}
+	}
+	for _, transfer := range i.activeRestarts() {
+		if transfer.point.restarts[transfer.restart].Name.Lexeme == name.Name {
+			transfer.arguments = arguments[1:]
+			return nil, transfer
+		}
+	}
+	return nil, &RuntimeError{Message: "No active restart named " + name.Name}
+}
+
+// computeRestarts returns a list of the names of the active restarts, innermost first
+func computeRestarts(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	active := i.activeRestarts()
+	names := make([]interface{}, len(active))
+	for j, transfer := range active {
+		names[j] = Symbol{Name: transfer.point.restarts[transfer.restart].Name.Lexeme}
+	}
+	return NewList(names...), nil
+}
+
+// conditionQ returns true if the argument is a condition, which includes errors
+func conditionQ(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	_, ok := asCondition(arguments[0])
+	return truthValue(ok), nil
+}
+
+// conditionMessage returns the message of a condition
+func conditionMessage(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	c, ok := asCondition(arguments[0])
+	if !ok {
+		return nil, &RuntimeError{Message: "CONDITION-MESSAGE operation must have a condition as the first operand"}
+	}
+	return c.Message, nil
+}
+
+// conditionKind returns the kind of a condition as a symbol
+func conditionKind(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	c, ok := asCondition(arguments[0])
+	if !ok {
+		return nil, &RuntimeError{Message: "CONDITION-KIND operation must have a condition as the first operand"}
+	}
+	return c.Kind, nil
+}
+
+// conditionData returns the list of data values a condition was made with
+func conditionData(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	c, ok := asCondition(arguments[0])
+	if !ok {
+		return nil, &RuntimeError{Message: "CONDITION-DATA operation must have a condition as the first operand"}
+	}
+	return c.Data, nil
+}
@@ -0,0 +1,28 @@
+package interpreter
+
+import "testing"
+
+func TestDebuggerSkipsErrorsATryCatches(t *testing.T) {
+	tests := []struct {
+		source string
+		called bool
+	}{
+		{`(restart-case (try (car 1) (catch (e) "caught")) (r () 1))`, false},
+		{`(try (restart-case (car 1) (r () 1)) (catch (e) "caught"))`, false},
+		{`(restart-case (try (car 1) (finally 1)) (r () 1))`, true},
+		{`(restart-case (car 1) (r () 1))`, true},
+	}
+
+	for _, test := range tests {
+		i := NewInterpreter()
+		called := false
+		i.Debugger = func(condition Value, restarts []Restart) (int, string, bool) {
+			called = true
+			return 0, "", false
+		}
+		i.EvalString(test.source)
+		if called != test.called {
+			t.Errorf("%s: Debugger called is %v, want %v", test.source, called, test.called)
+		}
+	}
+}
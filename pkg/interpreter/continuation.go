@@ -0,0 +1,88 @@
+package interpreter
+
+// Continuation is the rest of the computation waiting on a call to call-with-current-continuation.
+// Continuations are escape-only: calling one abandons whatever is being evaluated and makes the
+// call/cc that captured it return the passed value, but only while that call/cc has not returned yet
+type Continuation struct {
+	active bool
+}
+
+// String returns a string representation of the continuation for debugging purposes
+func (k *Continuation) String() string {
+	return "<continuation>"
+}
+
+// Arity is -1 since a continuation takes its value optionally
+func (k *Continuation) Arity() int {
+	return -1
+}
+
+// Call unwinds evaluation back to the call/cc that captured the continuation, which returns the
+// argument, or nil if there is none
+func (k *Continuation) Call(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	if len(arguments) > 1 {
+		return nil, &RuntimeError{Message: "A continuation takes at most 1 argument"}
+	}
+	if !k.active {
+		return nil, &RuntimeError{Message: "Continuation called after its call/cc returned"}
+	}
+
+	var value interface{}
+	if len(arguments) == 1 {
+		value = arguments[0]
+	}
+	return nil, &continuationTransfer{continuation: k, value: value}
+}
+
+// continuationTransfer unwinds evaluation from a call to a continuation back to the call/cc that
+// captured it. Like a restartTransfer, it travels up as an error that try never catches
+type continuationTransfer struct {
+	continuation *Continuation
+	value        interface{}
+}
+
+func (c *continuationTransfer) Error() string {
+	return "continuation called outside of its call/cc"
+}
+
+// callCC calls its argument with the current continuation, as in (call/cc (lambda (return) ...)),
+// returning either what the function returns or the value the continuation is called with
+func callCC(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	function, ok := arguments[0].(LispCallable)
+	if !ok || (function.Arity() >= 0 && function.Arity() != 1) {
+		return nil, &RuntimeError{Message: "CALL/CC must have a function of 1 argument as the operand"}
+	}
+
+	k := &Continuation{active: true}
+	result, err := function.Call(i, []interface{}{k})
+	k.active = false
+
+	if transfer, ok := err.(*continuationTransfer); ok && transfer.continuation == k {
+		return transfer.value, nil
+	}
+	return result, err
+}
+
+// dynamicWind calls the thunk between calls to the before and after thunks, returning what the thunk
+// returns. after is called however control leaves the thunk, be it by returning, by an error or by a
+// continuation or restart escaping from it
+func dynamicWind(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	var thunks [3]LispCallable
+	for j, argument := range arguments {
+		thunk, ok := argument.(LispCallable)
+		if !ok || thunk.Arity() > 0 {
+			return nil, &RuntimeError{Message: "DYNAMIC-WIND operands must be functions of no arguments"}
+		}
+		thunks[j] = thunk
+	}
+	before, thunk, after := thunks[0], thunks[1], thunks[2]
+
+	if _, err := before.Call(i, nil); err != nil {
+		return nil, err
+	}
+	result, err := thunk.Call(i, nil)
+	if _, afterErr := after.Call(i, nil); afterErr != nil {
+		return nil, afterErr
+	}
+	return result, err
+}
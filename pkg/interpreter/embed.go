@@ -0,0 +1,163 @@
+package interpreter
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Value is any value the language can work with: nil, true, numbers, strings, Symbols,
+// *Pairs and callables
+type Value = interface{}
+
+// HostFunc is the signature of a Go function registered with RegisterFunc. Arguments are
+// converted with FromValue before the call and the result is converted back with ToValue
+type HostFunc func(args ...Value) (Value, error)
+
+// Define binds name to a Go value in the global environment, converting it with ToValue
+func (i *Interpreter) Define(name string, value interface{}) error {
+	converted, err := ToValue(value)
+	if err != nil {
+		return err
+	}
+	i.globals.define(i.symbolName(name), converted)
+	return nil
+}
+
+// RegisterFunc binds name to a Go function in the global environment, so scripts can call it like
+// any other function. Errors returned by fn are raised as runtime errors at the call site
+func (i *Interpreter) RegisterFunc(name string, fn HostFunc) {
+	name = i.symbolName(name)
+	i.globals.define(name, hostBuiltin(name, fn))
+}
+
+// Lookup returns the global value bound to name, converted with FromValue
+func (i *Interpreter) Lookup(name string) (Value, bool) {
+	value, ok := i.globals.values[i.symbolName(name)]
+	if !ok {
+		return nil, false
+	}
+	return FromValue(value), true
+}
+
+// symbolName converts a name from Go into the symbol it is looked up as, following the interpreter's case sensitivity
+func (i *Interpreter) symbolName(name string) string {
+	if i.CaseSensitive {
+		return name
+	}
+	return strings.ToLower(name)
+}
+
+// hostBuiltin wraps a HostFunc into a variadic builtin
+func hostBuiltin(name string, fn HostFunc) Builtin {
+	return Builtin{Name: name, arity: -1, Fn: func(i *Interpreter, arguments []interface{}) (interface{}, error) {
+		args := make([]Value, len(arguments))
+		for j, argument := range arguments {
+			args[j] = FromValue(argument)
+		}
+
+		result, err := fn(args...)
+		if err != nil {
+			if _, ok := err.(*RuntimeError); ok {
+				return nil, err
+			}
+			return nil, &RuntimeError{Message: err.Error()}
+		}
+		return ToValue(result)
+	}}
+}
+
+// ToValue converts a Go value into a value of the language. Integers become int64 (or *big.Int if they
+// are too large) and floats become float64, bools become true or nil, slices and arrays become lists,
+// maps become association lists of (key . value) pairs sorted by key, and HostFuncs become functions.
+// Values that already belong to the language are returned as-is
+func ToValue(value interface{}) (Value, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case bool:
+		return truthValue(v), nil
+	case string, int64, float64, *big.Rat, Symbol, *Pair, LispCallable:
+		return v, nil
+	case *big.Int:
+		return normalize(v), nil
+	case HostFunc:
+		return hostBuiltin("host", v), nil
+	case func(args ...Value) (Value, error):
+		return hostBuiltin("host", v), nil
+	}
+
+	reflected := reflect.ValueOf(value)
+	switch reflected.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return reflected.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return normalize(new(big.Int).SetUint64(reflected.Uint())), nil
+	case reflect.Float32, reflect.Float64:
+		return reflected.Float(), nil
+	case reflect.String:
+		return reflected.String(), nil
+	case reflect.Bool:
+		return truthValue(reflected.Bool()), nil
+	case reflect.Slice, reflect.Array:
+		elements := make([]interface{}, reflected.Len())
+		for j := range elements {
+			element, err := ToValue(reflected.Index(j).Interface())
+			if err != nil {
+				return nil, err
+			}
+			elements[j] = element
+		}
+		return NewList(elements...), nil
+	case reflect.Map:
+		keys := reflected.MapKeys()
+		sort.Slice(keys, func(a, b int) bool {
+			return fmt.Sprint(keys[a].Interface()) < fmt.Sprint(keys[b].Interface())
+		})
+		entries := make([]interface{}, len(keys))
+		for j, key := range keys {
+			k, err := ToValue(key.Interface())
+			if err != nil {
+				return nil, err
+			}
+			v, err := ToValue(reflected.MapIndex(key).Interface())
+			if err != nil {
+				return nil, err
+			}
+			entries[j] = NewPair(k, v)
+		}
+		return NewList(entries...), nil
+	case reflect.Pointer, reflect.Interface:
+		if reflected.IsNil() {
+			return nil, nil
+		}
+		return ToValue(reflected.Elem().Interface())
+	}
+
+	return nil, fmt.Errorf("cannot convert value of type %T", value)
+}
+
+// FromValue converts a value of the language into a plain Go value. Proper lists become
+// []interface{} with their elements converted, Symbols become their names, and everything else
+// (numbers, strings, true, nil, improper lists and functions) is returned as-is
+func FromValue(value Value) interface{} {
+	switch v := value.(type) {
+	case Symbol:
+		return v.Name
+	case *Pair:
+		var elements []interface{}
+		var rest interface{} = v
+		for rest != nil {
+			pair, ok := rest.(*Pair)
+			if !ok { // improper lists have no slice equivalent
+				return v
+			}
+			elements = append(elements, FromValue(pair.Car))
+			rest = pair.Cdr
+		}
+		return elements
+	}
+	return value
+}
@@ -0,0 +1,164 @@
+package interpreter
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestToValue(t *testing.T) {
+	var nilPointer *int
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"int", 42, "42"},
+		{"int8", int8(-3), "-3"},
+		{"small uint", uint8(7), "7"},
+		{"large uint", uint64(math.MaxUint64), "18446744073709551615"},
+		{"float32", float32(2.5), "2.5"},
+		{"string", "hi", "hi"},
+		{"true", true, "true"},
+		{"false", false, "nil"},
+		{"nil pointer", nilPointer, "nil"},
+		{"slice", []int{1, 2, 3}, "(1 2 3)"},
+		{"nested slice", [][]string{{"a"}, {"b", "c"}}, "((a) (b c))"},
+		{"map sorted by key", map[string]int{"b": 2, "c": 3, "a": 1}, "((a . 1) (b . 2) (c . 3))"},
+	}
+
+	for _, test := range tests {
+		value, err := ToValue(test.value)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if got := stringify(value); got != test.want {
+			t.Errorf("%s: got %s, want %s", test.name, got, test.want)
+		}
+	}
+}
+
+func TestToValueNumberTypes(t *testing.T) {
+	small, _ := ToValue(uint32(7))
+	if _, ok := small.(int64); !ok {
+		t.Errorf("uint32 became %T, want int64", small)
+	}
+	large, _ := ToValue(uint64(math.MaxUint64))
+	if _, ok := large.(*big.Int); !ok {
+		t.Errorf("MaxUint64 became %T, want *big.Int", large)
+	}
+	fitting, _ := ToValue(big.NewInt(5))
+	if _, ok := fitting.(int64); !ok {
+		t.Errorf("a *big.Int that fits became %T, want int64", fitting)
+	}
+}
+
+func TestToValueUnsupported(t *testing.T) {
+	if _, err := ToValue(make(chan int)); err == nil {
+		t.Error("converting a channel succeeded, want an error")
+	}
+	if _, err := ToValue([]interface{}{1, make(chan int)}); err == nil {
+		t.Error("converting a slice holding a channel succeeded, want an error")
+	}
+}
+
+func TestFromValue(t *testing.T) {
+	if got := FromValue(Symbol{Name: "x"}); got != "x" {
+		t.Errorf("symbol became %v, want x", got)
+	}
+
+	list := NewList(int64(1), NewList(Symbol{Name: "a"}), "s")
+	want := []interface{}{int64(1), []interface{}{"a"}, "s"}
+	if got := FromValue(list); !reflect.DeepEqual(got, want) {
+		t.Errorf("list became %#v, want %#v", got, want)
+	}
+
+	improper := NewDottedList(int64(3), int64(1), int64(2))
+	if got := FromValue(improper); got != improper {
+		t.Errorf("improper list became %#v, want it returned as it is", got)
+	}
+}
+
+func TestDefineAndLookup(t *testing.T) {
+	i := NewInterpreter()
+	if err := i.Define("Limits", map[string]int{"max": 10}); err != nil {
+		t.Fatal(err)
+	}
+	value, err := i.EvalString("(cdr (car limits))")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != int64(10) {
+		t.Errorf("got %v, want 10", value)
+	}
+
+	if _, err := i.EvalString("(define total (+ 1 2))"); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := i.Lookup("TOTAL"); !ok || got != int64(3) {
+		t.Errorf("Lookup(TOTAL) = %v, %v, want 3, true", got, ok)
+	}
+	if _, ok := i.Lookup("missing"); ok {
+		t.Error("Lookup(missing) found a value")
+	}
+	if err := i.Define("bad", make(chan int)); err == nil {
+		t.Error("defining a channel succeeded, want an error")
+	}
+}
+
+func TestDefineCaseSensitive(t *testing.T) {
+	i := NewInterpreter()
+	i.CaseSensitive = true
+	i.Define("Name", "x")
+	if _, ok := i.Lookup("name"); ok {
+		t.Error("Lookup(name) found Name in a case sensitive interpreter")
+	}
+	if got, ok := i.Lookup("Name"); !ok || got != "x" {
+		t.Errorf("Lookup(Name) = %v, %v, want x, true", got, ok)
+	}
+}
+
+func TestRegisterFunc(t *testing.T) {
+	i := NewInterpreter()
+	var received []Value
+	i.RegisterFunc("Collect", func(args ...Value) (Value, error) {
+		received = args
+		return []int{len(args)}, nil
+	})
+
+	value, err := i.EvalString("(car (collect 'a '(1 2) 3))")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != int64(3) {
+		t.Errorf("got %v, want 3", value)
+	}
+	want := []Value{"a", []interface{}{int64(1), int64(2)}, int64(3)}
+	if !reflect.DeepEqual(received, want) {
+		t.Errorf("host function received %#v, want %#v", received, want)
+	}
+}
+
+func TestRegisterFuncErrors(t *testing.T) {
+	i := NewInterpreter()
+	i.RegisterFunc("fail", func(args ...Value) (Value, error) {
+		return nil, errors.New("host failure")
+	})
+
+	value, err := i.EvalString(`(try (fail) (catch (e) (error-message e)))`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != "host failure" {
+		t.Errorf("caught %v, want host failure", value)
+	}
+
+	_, err = i.EvalString("(fail)")
+	var runtimeErr *RuntimeError
+	if !errors.As(err, &runtimeErr) || runtimeErr.Message != "host failure" {
+		t.Errorf("got error %v, want a RuntimeError with the message host failure", err)
+	}
+}
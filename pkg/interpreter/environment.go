@@ -2,24 +2,24 @@ package interpreter
 
 import (
 	"golisp/pkg/scanner"
-	"os"
 )
 
-// Environment allows for variable scope and closures. Less necessary in this implementation of Lisp
+// Environment allows for variable scope and closures. Environments are always shared by pointer, so a
+// closure sees every later change to the bindings it captured, and changes it makes are seen by others
 type Environment struct {
 	enclosing *Environment
 	values    map[string]interface{}
 }
 
-func NewEnvironment() Environment {
-	return Environment{enclosing: nil, values: make(map[string]interface{})}
+func NewEnvironment() *Environment {
+	return &Environment{enclosing: nil, values: make(map[string]interface{})}
 }
 
-func NewEnvironmentWithEnclosing(Enclosing Environment) Environment {
-	return Environment{enclosing: &Enclosing, values: make(map[string]interface{})}
+func NewEnvironmentWithEnclosing(enclosing *Environment) *Environment {
+	return &Environment{enclosing: enclosing, values: make(map[string]interface{})}
 }
 
-// define a variable name as the passed value. only allowed in global scope
+// define a variable name as the passed value in this environment, shadowing any enclosing binding
 func (e *Environment) define(name string, value interface{}) {
 	e.values[name] = value // this allows for variable redefinition. May be weird in normal code, but is useful for REPL
 }
@@ -29,11 +29,30 @@ func (e *Environment) get(name scanner.Token) (interface{}, error) {
 	value, ok := e.values[name.Lexeme]
 	if !ok && e.enclosing != nil {
 		return e.enclosing.get(name)
-	} else if name.Lexeme == "exit" {
-		os.Exit(0)
-		return value, nil
 	} else if !ok {
 		return nil, &RuntimeError{Token: name, Message: "Undefined variable '" + name.Lexeme + "'."}
 	}
 	return value, nil
 }
+
+// resolve returns the nearest environment that binds a variable name, or nil if none of them do
+func (e *Environment) resolve(name string) *Environment {
+	if _, ok := e.values[name]; ok {
+		return e
+	}
+	if e.enclosing != nil {
+		return e.enclosing.resolve(name)
+	}
+	return nil
+}
+
+// assign changes the value of the nearest existing binding of a variable name, searching enclosing
+// environments like get. Unlike define it never creates a binding, and throws if there is none
+func (e *Environment) assign(name scanner.Token, value interface{}) error {
+	env := e.resolve(name.Lexeme)
+	if env == nil {
+		return &RuntimeError{Token: name, Message: "Undefined variable '" + name.Lexeme + "'."}
+	}
+	env.values[name.Lexeme] = value
+	return nil
+}
@@ -1,7 +1,9 @@
 package interpreter
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	"golisp/pkg/scanner"
 )
@@ -11,9 +13,101 @@ import (
 type RuntimeError struct {
 	Token   scanner.Token
 	Message string
+	Trace   []Frame    // the functions that were being called when the error occurred, innermost first
+	Value   *LispError // the error as a condition, set by the error builtin or once the error is signalled
+
+	signalled bool   // whether handlers have been offered the error yet
+	source    string // the file of the source whose evaluation the error ended, which may not hold Token
+}
+
+// tokenAt returns a token covering span, for errors about expressions that have no token of their own
+func tokenAt(span scanner.Span) scanner.Token {
+	return scanner.Token{Line: span.Line, Column: span.Column, File: span.File, Start: span.Start, End: span.End}
 }
 
 func (r *RuntimeError) Error() string {
-	msg := fmt.Sprintf("[line %d] Runtime Error: %s", r.Token.Line, r.Message)
-	return msg
+	return r.header() + r.traceback()
+}
+
+// header returns the error message without the traceback
+func (r *RuntimeError) header() string {
+	return fmt.Sprintf("[%s] Runtime Error: %s", r.Token.Span(), r.Message)
+}
+
+// traceback returns the lines of the traceback, or nothing if the error occurred outside of any function
+func (r *RuntimeError) traceback() string {
+	if len(r.Trace) == 0 {
+		return ""
+	}
+	return formatTrace(r.Token, r.Trace)
+}
+
+// surfaced records that err ended the evaluation of the source named file, so that FormatError only
+// underlines its position in that source. An error from a function defined in an earlier source, such
+// as a previous line of the REPL, has a position that the source being formatted does not hold
+func surfaced(err error, file string) error {
+	if runtimeErr, ok := err.(*RuntimeError); ok {
+		runtimeErr.source = file
+	}
+	return err
+}
+
+// value returns the error as a value that Lisp code can inspect once it has been caught
+func (r *RuntimeError) value() *LispError {
+	if r.Value != nil {
+		return r.Value
+	}
+	return &LispError{Condition: Condition{Kind: Symbol{Name: "runtime-error"}, Message: r.Message}}
+}
+
+// LispError is the value of an error caught by try, which is a kind of Condition. Errors raised with the
+// error builtin have the kind error unless another one is given, while errors raised by the interpreter
+// have the kind runtime-error
+type LispError struct {
+	Condition
+}
+
+// ExitError is raised by the exit builtin. It unwinds evaluation like any other error, leaving it up to
+// the host (such as the command line interpreter) to decide whether to actually end the process
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit with status %d", e.Code)
+}
+
+// FormatError returns the message of err followed by the line of source it points at, with the
+// offending code underlined by carets. Errors without a position in source, or with a position in
+// another source than the one they ended the evaluation of, are returned as they are
+func FormatError(err error, source string) string {
+	var diagnostics scanner.Diagnostics
+	if errors.As(err, &diagnostics) {
+		messages := make([]string, len(diagnostics))
+		for j, diagnostic := range diagnostics {
+			messages[j] = withUnderline(diagnostic.Error(), source, diagnostic.Span)
+		}
+		return strings.Join(messages, "\n")
+	}
+
+	var diagnostic scanner.Diagnostic
+	if errors.As(err, &diagnostic) {
+		return withUnderline(diagnostic.Error(), source, diagnostic.Span)
+	}
+
+	var runtimeErr *RuntimeError
+	if errors.As(err, &runtimeErr) && runtimeErr.Token.Line > 0 && runtimeErr.Token.File == runtimeErr.source {
+		return withUnderline(runtimeErr.header(), source, runtimeErr.Token.Span()) + runtimeErr.traceback()
+	}
+
+	return err.Error()
+}
+
+// withUnderline appends the underlined source line of span to message, if there is one
+func withUnderline(message string, source string, span scanner.Span) string {
+	underline := scanner.Underline(source, span)
+	if underline == "" {
+		return message
+	}
+	return message + "\n" + underline
 }
@@ -3,8 +3,6 @@ package interpreter
 import (
 	// "fmt"
 	"reflect"
-
-	"golisp/pkg/scanner"
 	// "github.com/reilandeubank/golisp/pkg/parser"
 )
 
@@ -21,25 +19,32 @@ func isTruthy(object interface{}) bool {
 	return true
 }
 
+// truthValue maps Go booleans onto the language, where true is 'true' and false is 'nil'
+func truthValue(value bool) interface{} {
+	if value {
+		return true
+	}
+	return nil
+}
+
+// isEqual compares numbers by value, so that 1, 2/2 and 1.0 are all equal, and everything else with ==
 func isEqual(a interface{}, b interface{}) bool {
-	if a == nil && b == nil { // No implicit type conversion for equality, like Go
+	if a == nil && b == nil { // Other than between numbers there is no implicit type conversion for equality, like Go
 		return true
 	} else if a == nil {
 		return false
 	}
-	return a == b
-}
-
-func checkNumberOperand(operator scanner.Token, operand interface{}) (bool, error) {
-	if reflect.TypeOf(operand) == reflect.TypeOf(0.0) || reflect.TypeOf(operand) == reflect.TypeOf(0) {
-		return true, nil
+	if isNumber(a) && isNumber(b) {
+		comparison, _ := compareNumbers(a, b)
+		return comparison == 0
+	}
+	if !reflect.TypeOf(a).Comparable() { // functions can't be compared with == in Go
+		return false
 	}
-	return false, &RuntimeError{Token: operator, Message: "Operator must be a number"}
+	return a == b
 }
 
-func checkNumberOperands(operator scanner.Token, left interface{}, right interface{}) error {
-	if reflect.TypeOf(left) == reflect.TypeOf(0.0) && reflect.TypeOf(right) == reflect.TypeOf(0.0) {
-		return nil
-	}
-	return &RuntimeError{Token: operator, Message: "Operators must be numbers"}
+func isNumber(operand interface{}) bool {
+	_, ok := numberRank(operand)
+	return ok
 }
@@ -3,22 +3,57 @@ package interpreter
 import (
 	"fmt"
 	"golisp/pkg/parser"
+	"golisp/pkg/scanner"
+	"io"
 )
 
 type Interpreter struct {
+	// CaseSensitive keeps the case of symbols in source code passed to EvalString, and of names passed to
+	// Define, RegisterFunc and Lookup. By default symbols are lowercased, like the rest of the language
+	CaseSensitive bool
+
+	// Debugger, if set, is offered every error that no handler took care of while restarts are available,
+	// unless a try with a catch clause is about to catch it. It returns the index of the restart to invoke,
+	// along with source code for the arguments to invoke it with, or false to let the error unwind as usual
+	Debugger func(condition Value, restarts []Restart) (choice int, arguments string, ok bool)
+
 	environment *Environment
 	globals     *Environment
+	tail        bool            // set while dispatching an expression that is in tail position
+	frames      []Frame         // the calls to functions that have not returned yet, innermost last
+	handlers    [][]handler     // the handlers of each handler-bind being evaluated, innermost last
+	restarts    []*restartPoint // the restart-cases being evaluated, innermost last
+	catching    int             // how many tries with a catch clause are evaluating their body
+	sources     int             // how many sources EvalString has been given, to tell their positions apart
 }
 
 // NewInterpreter defines an interpreter instance where the environment and globals are the same environment
 func NewInterpreter() Interpreter {
 	global := NewEnvironment()
-	return Interpreter{environment: &global, globals: &global}
+	defineBuiltins(global)
+	return Interpreter{environment: global, globals: global}
 }
 
 // evaluate calls the Accept method on a single expression
 func (i *Interpreter) evaluate(expr parser.Expression) (interface{}, error) {
-	return expr.Accept(i)
+	i.tail = false
+	result, err := expr.Accept(i)
+	if err != nil {
+		err = i.raised(err)
+	}
+	return result, err
+}
+
+// evaluateTail calls the Accept method on an expression that may be in tail position. A function call
+// in tail position returns a tailCall instead of a value, which the trampoline in LispFunction.Call
+// then runs, so tail recursion does not grow the Go stack
+func (i *Interpreter) evaluateTail(expr parser.Expression, tail bool) (interface{}, error) {
+	i.tail = tail
+	result, err := expr.Accept(i)
+	if err != nil {
+		err = i.raised(err)
+	}
+	return result, err
 }
 
 // Interpret will evaluate all expressions in the source code, printing out returned values
@@ -29,21 +64,104 @@ func (i *Interpreter) Interpret(exprs []parser.Expression) error {
 			fmt.Println(out)
 		}
 		if err != nil {
-			return err
+			return surfaced(err, expr.Position().File)
 		}
 	}
 	return nil
 }
 
-// evaluateFunction will call evaluate the function's expression
+// EvalString scans, parses and evaluates source code, returning the value of the last expression.
+// Scan, parse and runtime errors are all returned rather than ending the process, which makes
+// it safe to call from programs embedding the interpreter. Positions in each source are given the file
+// name <string:N>, for the Nth source the interpreter has been given
+func (i *Interpreter) EvalString(source string) (Value, error) {
+	i.sources++
+	thisScanner := scanner.NewScanner(source)
+	thisScanner.File = fmt.Sprintf("<string:%d>", i.sources)
+	thisScanner.CaseSensitive = i.CaseSensitive
+	tokens := thisScanner.ScanTokens()
+	if thisScanner.HadError() {
+		return nil, thisScanner.Diagnostics
+	}
+
+	thisParser := parser.NewParser(tokens)
+	return i.run(&thisParser, false)
+}
+
+// Run parses and evaluates the forms read by thisParser one at a time, printing the value of each like
+// Interpret. Each form is parsed after the ones before it have been evaluated, so it can use any macro
+// they define, and the macro can call any function they define. Nothing is evaluated if the syntax of
+// any of the forms is wrong
+func (i *Interpreter) Run(thisParser *parser.Parser) error {
+	_, err := i.run(thisParser, true)
+	return err
+}
+
+// run is Run, returning the value of the last form. A syntax error only found once a macro has been
+// expanded stops the evaluation, but the rest of the forms are still parsed so that every syntax error
+// is reported
+func (i *Interpreter) run(thisParser *parser.Parser, print bool) (Value, error) {
+	thisParser.Macros = i
+	if diagnostics := thisParser.Check(); len(diagnostics) > 0 {
+		return nil, diagnostics
+	}
+
+	var result Value
+	for thisParser.HasNext() {
+		expr, ok := thisParser.Next()
+		if !ok || len(thisParser.Diagnostics) > 0 {
+			continue
+		}
+
+		var err error
+		result, err = i.evaluate(expr)
+		if print && result != nil {
+			fmt.Println(result)
+		}
+		if err != nil {
+			return nil, surfaced(err, thisParser.Tokens[0].File)
+		}
+	}
+
+	if len(thisParser.Diagnostics) > 0 {
+		return nil, thisParser.Diagnostics
+	}
+	return result, nil
+}
+
+// EvalReader is EvalString for source code read from r
+func (i *Interpreter) EvalReader(r io.Reader) (Value, error) {
+	source, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return i.EvalString(string(source))
+}
+
+// evaluateFunction will evaluate the body of a function (or let) in the passed environment
 // and then return the current environment to normal after completion
-func (i *Interpreter) evaluateFunction(expression parser.Expression, environment Environment) (interface{}, error) {
+func (i *Interpreter) evaluateFunction(body []parser.Expression, environment *Environment, tail bool) (interface{}, error) {
 	previous := i.environment
 
 	defer func() {
 		i.environment = previous
 	}()
 
-	i.environment = &environment
-	return i.evaluate(expression)
+	i.environment = environment
+	return i.evaluateSequence(body, tail)
+}
+
+// evaluateSequence evaluates each expression in order and returns the value of the last one,
+// which is the only one that can be in tail position
+func (i *Interpreter) evaluateSequence(exprs []parser.Expression, tail bool) (interface{}, error) {
+	if len(exprs) == 0 {
+		return nil, nil
+	}
+	for _, expr := range exprs[:len(exprs)-1] {
+		_, err := i.evaluate(expr)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return i.evaluateTail(exprs[len(exprs)-1], tail)
 }
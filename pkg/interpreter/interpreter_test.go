@@ -0,0 +1,35 @@
+package interpreter
+
+import (
+	"errors"
+	"testing"
+
+	"golisp/pkg/scanner"
+)
+
+func TestEvalStringChecksSyntaxBeforeEvaluating(t *testing.T) {
+	i := NewInterpreter()
+	_, err := i.EvalString("(defvar x 1) (car 1) (1 2 . ) (define broken (x)")
+
+	var diagnostics scanner.Diagnostics
+	if !errors.As(err, &diagnostics) {
+		t.Fatalf("got error %v, want scanner.Diagnostics", err)
+	}
+	if len(diagnostics) != 2 {
+		t.Errorf("got %d syntax errors, want 2: %v", len(diagnostics), diagnostics)
+	}
+	if _, ok := i.Lookup("x"); ok {
+		t.Error("x was defined, but nothing should be evaluated")
+	}
+}
+
+func TestEvalStringExpandsMacrosDefinedEarlier(t *testing.T) {
+	i := NewInterpreter()
+	value, err := i.EvalString("(define twice (x) (list 'begin x x)) (defmacro m (x) (twice x)) (m (+ 1 1))")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != int64(2) {
+		t.Errorf("got %v, want 2", value)
+	}
+}
@@ -0,0 +1,217 @@
+package interpreter
+
+import (
+	"errors"
+	"fmt"
+
+	"golisp/pkg/parser"
+	"golisp/pkg/scanner"
+)
+
+// Macro is the value a defmacro binds its name to. Macros are expanded while the code using them is
+// parsed: the forms of a call are passed to the macro unevaluated, as data, and the form it returns is
+// parsed in place of the call. Top-level forms are parsed and evaluated one at a time, so a macro can be
+// used by the forms after its definition, and can call functions defined before the form it is used in
+type Macro struct {
+	Declaration parser.MacroDefinition
+	Closure     *Environment
+}
+
+// String returns a string representation of the macro for debugging purposes
+func (m *Macro) String() string {
+	return "<macro " + m.Declaration.Name.Lexeme + ">"
+}
+
+// expand runs the macro on the forms of a call made at token, with any forms beyond its parameters
+// bound to its &rest parameter as a list
+func (m *Macro) expand(i *Interpreter, token scanner.Token, forms []interface{}) (interface{}, error) {
+	params := m.Declaration.Params
+	if m.Declaration.Rest == nil && len(forms) != len(params) {
+		return nil, &RuntimeError{Token: token, Message: fmt.Sprintf("Expected %d arguments but got %d.", len(params), len(forms))}
+	}
+	if len(forms) < len(params) {
+		return nil, &RuntimeError{Token: token, Message: fmt.Sprintf("Expected at least %d arguments but got %d.", len(params), len(forms))}
+	}
+
+	if err := i.pushFrame(Frame{Function: m.Declaration.Name.Lexeme, Call: token, Arguments: forms}); err != nil {
+		return nil, err
+	}
+	defer i.popFrame()
+
+	env := NewEnvironmentWithEnclosing(m.Closure)
+	for j, param := range params {
+		env.define(param.Lexeme, forms[j])
+	}
+	if m.Declaration.Rest != nil {
+		env.define(m.Declaration.Rest.Lexeme, NewList(forms[len(params):]...))
+	}
+
+	expansion, err := i.evaluateFunction(m.Declaration.Body, env, false)
+	if err != nil {
+		return nil, i.traceback(err)
+	}
+	return expansion, nil
+}
+
+// macro returns the macro bound to name, if there is one
+func (i *Interpreter) macro(name string) (*Macro, bool) {
+	env := i.environment.resolve(name)
+	if env == nil {
+		return nil, false
+	}
+	m, ok := env.values[name].(*Macro)
+	return m, ok
+}
+
+// expandOnce expands form if it is a call to a macro, reporting whether it was one
+func (i *Interpreter) expandOnce(form interface{}, token scanner.Token) (interface{}, bool, error) {
+	call, ok := form.(*Pair)
+	if !ok {
+		return form, false, nil
+	}
+	name, ok := call.Car.(Symbol)
+	if !ok {
+		return form, false, nil
+	}
+	m, ok := i.macro(name.Name)
+	if !ok {
+		return form, false, nil
+	}
+
+	var forms []interface{}
+	for rest := call.Cdr; rest != nil; {
+		pair, ok := rest.(*Pair)
+		if !ok {
+			return nil, false, &RuntimeError{Token: token, Message: "A macro call must be a proper list"}
+		}
+		forms = append(forms, pair.Car)
+		rest = pair.Cdr
+	}
+
+	expansion, err := m.expand(i, token, forms)
+	return expansion, true, err
+}
+
+// IsMacro reports whether name is bound to a macro
+func (i *Interpreter) IsMacro(name string) bool {
+	_, ok := i.macro(name)
+	return ok
+}
+
+// ExpandMacro expands a macro call read by the parser, and returns the tokens of the expansion for the
+// parser to parse in place of the call. The tokens are positioned at the call for error messages
+func (i *Interpreter) ExpandMacro(call parser.ListExpr) ([]scanner.Token, error) {
+	token := call.Head.(parser.Symbol).Name
+	expansion, _, err := i.expandOnce(datumValue(call), token)
+	if runtimeErr, ok := err.(*RuntimeError); ok {
+		return nil, errors.New(runtimeErr.Message)
+	} else if err != nil {
+		return nil, err
+	}
+
+	return formTokens(expansion, call.Position())
+}
+
+// formTokens converts a form built by a macro into the tokens it would be read from, so that it can be
+// parsed like source code. Every token is placed at span
+func formTokens(form interface{}, span scanner.Span) ([]scanner.Token, error) {
+	var tokens []scanner.Token
+	add := func(tokenType scanner.TokenType, lexeme string, literal interface{}) {
+		tokens = append(tokens, scanner.Token{
+			Type:    tokenType,
+			Lexeme:  lexeme,
+			Literal: literal,
+			Line:    span.Line,
+			Column:  span.Column,
+			File:    span.File,
+			Start:   span.Start,
+			End:     span.End,
+		})
+	}
+
+	var write func(form interface{}) error
+	write = func(form interface{}) error {
+		switch f := form.(type) {
+		case nil: // written as () since nil may stand for an empty parameter or binding list
+			add(scanner.LEFT_PAREN, "(", nil)
+			add(scanner.RIGHT_PAREN, ")", nil)
+		case bool: // false only comes from Go, and is written as nil like the language's own false
+			if !f {
+				return write(nil)
+			}
+			add(scanner.TRUE, "true", nil)
+		case string:
+			add(scanner.STRING, f, f)
+		case Symbol:
+			if tokenType, ok := scanner.Keywords[f.Name]; ok {
+				add(tokenType, f.Name, nil)
+			} else if tokenType, ok := operatorType(f.Name); ok {
+				add(tokenType, f.Name, nil)
+			} else {
+				add(scanner.SYMBOL, f.Name, nil)
+			}
+		case *Pair:
+			add(scanner.LEFT_PAREN, "(", nil)
+			var rest interface{} = f
+			for rest != nil {
+				pair, ok := rest.(*Pair)
+				if !ok {
+					add(scanner.DOT, ".", nil)
+					if err := write(rest); err != nil {
+						return err
+					}
+					break
+				}
+				if err := write(pair.Car); err != nil {
+					return err
+				}
+				rest = pair.Cdr
+			}
+			add(scanner.RIGHT_PAREN, ")", nil)
+		default:
+			if _, ok := numberRank(form); !ok {
+				return errors.New("the expansion contains " + stringify(form) + ", which cannot be written as code")
+			}
+			add(scanner.NUMBER, stringify(form), form)
+		}
+		return nil
+	}
+
+	if err := write(form); err != nil {
+		return nil, err
+	}
+	add(scanner.EOF, "EOF", nil)
+	return tokens, nil
+}
+
+// operatorType returns the token type of the operator written as name, including /= for !=
+func operatorType(name string) (scanner.TokenType, bool) {
+	if name == "/=" {
+		return scanner.BANG_EQUAL, true
+	}
+	for tokenType, operator := range operators {
+		if operator.Name == name {
+			return tokenType, true
+		}
+	}
+	return 0, false
+}
+
+// macroexpand1 expands its argument once if it is a macro call, and returns it unchanged otherwise
+func macroexpand1(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	expansion, _, err := i.expandOnce(arguments[0], scanner.Token{})
+	return expansion, err
+}
+
+// macroexpand expands its argument until it is no longer a macro call. Macro calls nested inside the
+// result are left as they are
+func macroexpand(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	form := arguments[0]
+	for {
+		expansion, expanded, err := i.expandOnce(form, scanner.Token{})
+		if err != nil || !expanded {
+			return expansion, err
+		}
+		form = expansion
+	}
+}
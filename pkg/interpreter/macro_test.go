@@ -0,0 +1,28 @@
+package interpreter
+
+import (
+	"testing"
+
+	"golisp/pkg/scanner"
+)
+
+func TestFormTokensWritesFalseAsNil(t *testing.T) {
+	tokens, err := formTokens(NewList(Symbol{Name: "list"}, false, true), scanner.Span{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lexemes []string
+	for _, token := range tokens {
+		lexemes = append(lexemes, token.Lexeme)
+	}
+	want := []string{"(", "list", "(", ")", "true", ")", "EOF"}
+	if len(lexemes) != len(want) {
+		t.Fatalf("got %v, want %v", lexemes, want)
+	}
+	for j := range want {
+		if lexemes[j] != want[j] {
+			t.Fatalf("got %v, want %v", lexemes, want)
+		}
+	}
+}
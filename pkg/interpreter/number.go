@@ -0,0 +1,185 @@
+package interpreter
+
+import (
+	"cmp"
+	"math"
+	"math/big"
+)
+
+// Numbers form a tower of four types, each able to hold every value of the ones below it: int64
+// fixnums, *big.Int bignums for integers too large for a fixnum, *big.Rat for exact fractions, and
+// float64 for inexact numbers, which are only written with a decimal point. Arithmetic on exact
+// numbers stays exact, and its result is always stored as the lowest type that can hold it
+const (
+	fixnum = iota
+	bignum
+	rational
+	flonum
+)
+
+// numberRank returns the level of the tower that a number belongs to, or false if it is not a number
+func numberRank(number interface{}) (int, bool) {
+	switch number.(type) {
+	case int64:
+		return fixnum, true
+	case *big.Int:
+		return bignum, true
+	case *big.Rat:
+		return rational, true
+	case float64:
+		return flonum, true
+	}
+	return 0, false
+}
+
+// toBig returns an integer as a bignum
+func toBig(number interface{}) *big.Int {
+	if n, ok := number.(int64); ok {
+		return big.NewInt(n)
+	}
+	return number.(*big.Int)
+}
+
+// toRat returns an exact number as a rational
+func toRat(number interface{}) *big.Rat {
+	switch n := number.(type) {
+	case int64:
+		return new(big.Rat).SetInt64(n)
+	case *big.Int:
+		return new(big.Rat).SetInt(n)
+	}
+	return number.(*big.Rat)
+}
+
+// toFloat returns any number as a float, losing precision if it does not fit
+func toFloat(number interface{}) float64 {
+	switch n := number.(type) {
+	case int64:
+		return float64(n)
+	case *big.Int:
+		f, _ := new(big.Float).SetInt(n).Float64()
+		return f
+	case *big.Rat:
+		f, _ := n.Float64()
+		return f
+	}
+	return number.(float64)
+}
+
+// normalize moves an exact number down to the lowest level of the tower that can hold it, so a
+// rational with a denominator of 1 becomes an integer, and an integer that fits a fixnum becomes one
+func normalize(number interface{}) interface{} {
+	if r, ok := number.(*big.Rat); ok {
+		if !r.IsInt() {
+			return r
+		}
+		number = new(big.Int).Set(r.Num())
+	}
+	if n, ok := number.(*big.Int); ok && n.IsInt64() {
+		return n.Int64()
+	}
+	return number
+}
+
+// arithmetic is an operation on two numbers, implemented for each level of the tower. The fixnum
+// version reports false when its result overflows, in which case the bignum version is used instead
+type arithmetic struct {
+	fixnum   func(a, b int64) (int64, bool)
+	bignum   func(z, a, b *big.Int) *big.Int
+	rational func(z, a, b *big.Rat) *big.Rat
+	flonum   func(a, b float64) float64
+}
+
+var addition = arithmetic{
+	fixnum: func(a, b int64) (int64, bool) {
+		sum := a + b
+		return sum, (sum > a) == (b > 0)
+	},
+	bignum:   (*big.Int).Add,
+	rational: (*big.Rat).Add,
+	flonum:   func(a, b float64) float64 { return a + b },
+}
+
+var subtraction = arithmetic{
+	fixnum: func(a, b int64) (int64, bool) {
+		difference := a - b
+		return difference, (difference < a) == (b > 0)
+	},
+	bignum:   (*big.Int).Sub,
+	rational: (*big.Rat).Sub,
+	flonum:   func(a, b float64) float64 { return a - b },
+}
+
+var multiplication = arithmetic{
+	fixnum: func(a, b int64) (int64, bool) {
+		if a == 0 || b == 0 {
+			return 0, true
+		}
+		product := a * b
+		overflow := product/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64)
+		return product, !overflow
+	},
+	bignum:   (*big.Int).Mul,
+	rational: (*big.Rat).Mul,
+	flonum:   func(a, b float64) float64 { return a * b },
+}
+
+// apply performs the operation at the highest level of the tower that either operand belongs to
+func (op arithmetic) apply(left interface{}, right interface{}) (interface{}, error) {
+	leftRank, leftOk := numberRank(left)
+	rightRank, rightOk := numberRank(right)
+	if !leftOk || !rightOk {
+		return nil, &RuntimeError{Message: "Operators must be numbers"}
+	}
+
+	switch max(leftRank, rightRank) {
+	case flonum:
+		return op.flonum(toFloat(left), toFloat(right)), nil
+	case rational:
+		return normalize(op.rational(new(big.Rat), toRat(left), toRat(right))), nil
+	case fixnum:
+		if result, ok := op.fixnum(left.(int64), right.(int64)); ok {
+			return result, nil
+		}
+	}
+	return normalize(op.bignum(new(big.Int), toBig(left), toBig(right))), nil
+}
+
+// quotient divides two numbers. Dividing exact numbers gives an exact result, which is a rational
+// unless it divides evenly, while dividing floats follows Go and may give an infinity
+func quotient(left interface{}, right interface{}) (interface{}, error) {
+	leftRank, leftOk := numberRank(left)
+	rightRank, rightOk := numberRank(right)
+	if !leftOk || !rightOk {
+		return nil, &RuntimeError{Message: "Operators must be numbers"}
+	}
+
+	if max(leftRank, rightRank) == flonum {
+		return toFloat(left) / toFloat(right), nil
+	}
+	divisor := toRat(right)
+	if divisor.Sign() == 0 {
+		return nil, &RuntimeError{Message: "Division by zero"}
+	}
+	return normalize(new(big.Rat).Quo(toRat(left), divisor)), nil
+}
+
+// compareNumbers returns -1, 0 or 1 as left is less than, equal to or greater than right.
+// Exact numbers are compared exactly, unless one of them is a float
+func compareNumbers(left interface{}, right interface{}) (int, error) {
+	leftRank, leftOk := numberRank(left)
+	rightRank, rightOk := numberRank(right)
+	if !leftOk || !rightOk {
+		return 0, &RuntimeError{Message: "Operators must be numbers"}
+	}
+
+	switch max(leftRank, rightRank) {
+	case flonum:
+		return cmp.Compare(toFloat(left), toFloat(right)), nil
+	case rational:
+		return toRat(left).Cmp(toRat(right)), nil
+	case bignum:
+		return toBig(left).Cmp(toBig(right)), nil
+	}
+	return cmp.Compare(left.(int64), right.(int64)), nil
+}
@@ -0,0 +1,72 @@
+package interpreter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Pair is a cons cell, the building block of every list in the language. A proper list is a chain
+// of pairs whose final Cdr is nil, while any other final Cdr makes an improper (dotted) list
+type Pair struct {
+	Car interface{}
+	Cdr interface{}
+}
+
+// NewPair allocates a new cons cell
+func NewPair(car interface{}, cdr interface{}) *Pair {
+	return &Pair{Car: car, Cdr: cdr}
+}
+
+// NewList builds a proper list out of the passed elements, with an empty list being nil
+func NewList(elements ...interface{}) interface{} {
+	return NewDottedList(nil, elements...)
+}
+
+// NewDottedList builds a list out of the passed elements that ends in tail instead of nil
+func NewDottedList(tail interface{}, elements ...interface{}) interface{} {
+	list := tail
+	for j := len(elements) - 1; j >= 0; j-- {
+		list = NewPair(elements[j], list)
+	}
+	return list
+}
+
+// String prints the pair in list notation, falling back to dot notation for improper tails
+func (p *Pair) String() string {
+	var builder strings.Builder
+	builder.WriteString("(")
+	builder.WriteString(stringify(p.Car))
+
+	rest := p.Cdr
+	for rest != nil {
+		next, ok := rest.(*Pair)
+		if !ok {
+			builder.WriteString(" . ")
+			builder.WriteString(stringify(rest))
+			break
+		}
+		builder.WriteString(" ")
+		builder.WriteString(stringify(next.Car))
+		rest = next.Cdr
+	}
+
+	builder.WriteString(")")
+	return builder.String()
+}
+
+// isList reports whether object can be treated as a list, which is either nil (the empty list) or a pair
+func isList(object interface{}) bool {
+	if object == nil {
+		return true
+	}
+	_, ok := object.(*Pair)
+	return ok
+}
+
+// stringify returns the printed representation of a runtime value
+func stringify(object interface{}) string {
+	if object == nil {
+		return "nil"
+	}
+	return fmt.Sprint(object)
+}
@@ -0,0 +1,93 @@
+package interpreter
+
+import (
+	"golisp/pkg/parser"
+)
+
+// Symbol is the runtime value of a quoted symbol, such as the result of evaluating 'x
+type Symbol struct {
+	Name string
+}
+
+// String returns the name of the symbol
+func (s Symbol) String() string {
+	return s.Name
+}
+
+// datumValue converts a quoted expression into data. Lists become cons cells, while symbols,
+// keywords and operators all become Symbols
+func datumValue(datum parser.Expression) interface{} {
+	switch d := datum.(type) {
+	case parser.Atom:
+		return d.Value
+	case parser.Symbol:
+		return Symbol{Name: d.Name.Lexeme}
+	case parser.Keyword:
+		return Symbol{Name: d.Keyword.Lexeme}
+	case parser.Operator:
+		return Symbol{Name: d.Operator.Lexeme}
+	case parser.ListExpr:
+		elements := make([]interface{}, len(d.Tail)+1)
+		elements[0] = datumValue(d.Head)
+		for j, expr := range d.Tail {
+			elements[j+1] = datumValue(expr)
+		}
+		var tail interface{}
+		if d.Dotted != nil {
+			tail = datumValue(d.Dotted)
+		}
+		return NewDottedList(tail, elements...)
+	}
+	return nil
+}
+
+// quasiquote converts a quasiquote template into data like datumValue, except that unquoted
+// expressions are evaluated and spliced expressions have their elements inserted into the list
+func (i *Interpreter) quasiquote(template parser.Expression) (interface{}, error) {
+	switch t := template.(type) {
+	case parser.Unquote:
+		if t.Splicing {
+			return nil, &RuntimeError{Token: t.Keyword, Message: "UNQUOTE-SPLICING must appear inside of a list"}
+		}
+		return i.evaluate(t.Expr)
+	case parser.ListExpr:
+		var elements []interface{}
+		for _, expr := range append([]parser.Expression{t.Head}, t.Tail...) {
+			if unquote, ok := expr.(parser.Unquote); ok && unquote.Splicing {
+				spliced, err := i.evaluate(unquote.Expr)
+				if err != nil {
+					return nil, err
+				}
+				if !isList(spliced) {
+					return nil, &RuntimeError{Token: unquote.Keyword, Message: "UNQUOTE-SPLICING must evaluate to a list"}
+				}
+				for spliced != nil {
+					pair, ok := spliced.(*Pair)
+					if !ok {
+						return nil, &RuntimeError{Token: unquote.Keyword, Message: "UNQUOTE-SPLICING must evaluate to a proper list"}
+					}
+					elements = append(elements, pair.Car)
+					spliced = pair.Cdr
+				}
+				continue
+			}
+
+			element, err := i.quasiquote(expr)
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, element)
+		}
+
+		var tail interface{}
+		if t.Dotted != nil {
+			var err error
+			tail, err = i.quasiquote(t.Dotted)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return NewDottedList(tail, elements...), nil
+	}
+	return datumValue(template), nil
+}
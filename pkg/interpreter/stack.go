@@ -0,0 +1,91 @@
+package interpreter
+
+import (
+	"fmt"
+	"strings"
+
+	"golisp/pkg/scanner"
+)
+
+// maxTraceFrames is how many frames of a traceback are printed before the rest are summarized,
+// so that an error deep inside a recursive function stays readable
+const maxTraceFrames = 20
+
+// maxCallDepth is how many calls can be in progress at once. Recursing any deeper raises a runtime error,
+// well before the Go stack the interpreter runs on would overflow and end the whole process
+const maxCallDepth = 10000
+
+// Frame is a call to a LispFunction that has not returned yet
+type Frame struct {
+	Function  string
+	Call      scanner.Token // where the function was called from, which has no position if called from Go
+	Arguments []interface{}
+}
+
+// String returns the call the frame was made for, such as (factorial 3)
+func (f Frame) String() string {
+	var builder strings.Builder
+	builder.WriteString("(" + f.Function)
+	for _, argument := range f.Arguments {
+		builder.WriteString(" " + stringify(argument))
+	}
+	builder.WriteString(")")
+	return builder.String()
+}
+
+// pushFrame records a call to a function on the interpreter's call stack, unless the stack is already
+// maxCallDepth frames deep
+func (i *Interpreter) pushFrame(frame Frame) error {
+	if len(i.frames) >= maxCallDepth {
+		return &RuntimeError{Token: frame.Call, Message: fmt.Sprintf("Stack overflow: more than %d nested calls.", maxCallDepth)}
+	}
+	i.frames = append(i.frames, frame)
+	return nil
+}
+
+// popFrame removes the innermost frame from the call stack
+func (i *Interpreter) popFrame() {
+	i.frames = i.frames[:len(i.frames)-1]
+}
+
+// traceback attaches a copy of the call stack to a RuntimeError that does not have one yet.
+// It is called as the error leaves the innermost function, while the stack still holds every frame
+func (i *Interpreter) traceback(err error) error {
+	if runtimeErr, ok := err.(*RuntimeError); ok && runtimeErr.Trace == nil {
+		runtimeErr.Trace = make([]Frame, len(i.frames))
+		for j, frame := range i.frames {
+			runtimeErr.Trace[len(i.frames)-1-j] = frame // innermost first
+		}
+	}
+	return err
+}
+
+// formatTrace returns a Lisp-level traceback, starting in the function the error occurred in and
+// following each call back out to the top level. Frames replaced by tail calls are not included
+func formatTrace(errorToken scanner.Token, trace []Frame) string {
+	var builder strings.Builder
+	location := errorToken
+	for j, frame := range trace {
+		if j == maxTraceFrames {
+			builder.WriteString(fmt.Sprintf("\n  ... %d more frames", len(trace)-j))
+			return builder.String()
+		}
+		if j == 0 {
+			builder.WriteString("\n  in " + frame.String())
+		} else {
+			builder.WriteString("\n  called from " + frame.String())
+		}
+		builder.WriteString(at(location))
+		location = frame.Call
+	}
+	builder.WriteString("\n  called from top level" + at(location))
+	return builder.String()
+}
+
+// at describes where a token is, or nothing if it has no position
+func at(t scanner.Token) string {
+	if t.Line == 0 {
+		return ""
+	}
+	return " at " + t.Span().String()
+}
@@ -11,64 +11,50 @@ import (
 // VisitListExpr evaluates a list which could be an operation, keyword and arguments, or a simple list of atoms
 func (i *Interpreter) VisitListExpr(l parser.ListExpr) (interface{}, error) {
 	switch head := l.Head.(type) {
-	// adds the tail as the operator's operands and evaluates the operator
+	// adds the tail as the operator's operands and evaluates the operator. Operands are never nil
+	// here, since a nil Operands marks an operator that is not being applied
 	case parser.Operator:
-		head.Operands = l.Tail
-		result, err := i.evaluate(head)
-		if err != nil {
-			return nil, err
-		}
-		if result == false {
-			return nil, nil
-		}
-		return result, nil
-	// adds the tail as the keyword's arguments and evaluates it
-	case parser.Keyword:
-		head.Args = l.Tail
+		head.Operands = append([]parser.Expression{}, l.Tail...)
 		return i.evaluate(head)
-	// will simply return a list of atoms
+	// adds the tail as the keyword's arguments and evaluates it, keeping any tail position. Like an
+	// operator's operands, the arguments are never nil here
+	case parser.Keyword:
+		head.Args = append([]parser.Expression{}, l.Tail...)
+		return head.Accept(i)
+	// evaluates each element and builds up a list of cons cells
 	case parser.Atom:
-		returnList := parser.ListExpr{Head: head, Tail: l.Tail}
-		return returnList, nil
+		elements := make([]interface{}, len(l.Tail)+1)
+		elements[0] = head.Value
+		for j, expr := range l.Tail {
+			element, err := i.evaluate(expr)
+			if err != nil {
+				return nil, err
+			}
+			elements[j+1] = element
+		}
+		var tail interface{}
+		if l.Dotted != nil {
+			var err error
+			tail, err = i.evaluate(l.Dotted)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return NewDottedList(tail, elements...), nil
 	}
 
-	return nil, fmt.Errorf("LISTEXPR not implemented")
+	return nil, &RuntimeError{Token: tokenAt(l.Head.Position()), Message: "Can only call functions."}
 }
 
 // VisitKeywordExpr evaluates a syntax node where the keyword is of one of the
 // below types, and it contains a list of "arguments
 func (i *Interpreter) VisitKeywordExpr(k parser.Keyword) (interface{}, error) {
+	tail := i.tail
 	switch k.Keyword.Type {
 	case scanner.TRUE: // TRUE keyword maps to Go's 'true' value
 		return true, nil
 	case scanner.NIL: // NIL keyword maps to Go's 'nil' value (is also treated like a false value)
 		return nil, nil
-	case scanner.CAR: // car returns the first element of a list as an atom
-		car, err := i.evaluate(k.Args[0])
-		switch car.(type) {
-		case parser.ListExpr:
-			car = car.(parser.ListExpr).Head
-			return i.evaluate(car.(parser.Expression))
-		}
-		if err != nil {
-			return nil, err
-		}
-		return car, nil
-	case scanner.CDR: // cdr returns the entire list other than the first element
-		output, err := i.evaluate(k.Args[0])
-		if err != nil {
-			return nil, err
-		}
-		list, ok := output.(parser.ListExpr)
-		if !ok {
-			return nil, &RuntimeError{Token: k.Keyword, Message: "CDR operation must have a list as the first operand"}
-		}
-
-		if len(list.Tail) > 1 {
-			return parser.ListExpr{Head: list.Tail[0], Tail: list.Tail[1:]}, nil
-		} else {
-			return parser.ListExpr{Head: list.Tail[0]}, nil
-		}
 	case scanner.COND: // cond is of the form (cond c1 r1 c2 r2...), where if c_n is true, r_n will be evaluated
 		for j := 0; j < len(k.Args); j += 2 {
 			condition, err := i.evaluate(k.Args[j])
@@ -76,156 +62,86 @@ func (i *Interpreter) VisitKeywordExpr(k parser.Keyword) (interface{}, error) {
 				return nil, err
 			}
 			if isTruthy(condition) && j+1 < len(k.Args) {
-				return i.evaluate(k.Args[j+1])
+				return i.evaluateTail(k.Args[j+1], tail)
 			}
 		}
 		return nil, &RuntimeError{Token: k.Keyword, Message: "Lack of true condition"}
-	case scanner.NUMBERQ: // number? returns true if the argument is of type 'number', else nil
-		if len(k.Args) != 1 {
-			return nil, &RuntimeError{Token: k.Keyword, Message: "NUMBER? operation must have 1 operand"}
+	case scanner.BEGIN: // begin evaluates each argument in order and returns the value of the last one
+		return i.evaluateSequence(k.Args, tail)
+	case scanner.SYMBOLQ: // symbol? returns true if the argument is a symbol, else nil. Anywhere but the head of a list it evaluates to its native function
+		if k.Args == nil {
+			return symbolQBuiltin, nil
 		}
-		expr, err := i.evaluate(k.Args[0])
-		if err != nil {
-			return nil, err
-		}
-		return checkNumberOperand(k.Keyword, expr)
-	case scanner.SYMBOLQ: // symbol? returns true if the argument is a symbol, else nil
 		if len(k.Args) != 1 {
 			return nil, &RuntimeError{Token: k.Keyword, Message: "SYMBOL? operation must have 1 operand"}
 		}
-		return reflect.TypeOf(k.Args[0]) == reflect.TypeOf(parser.Symbol{}), nil
-	case scanner.LISTQ: // list? returns true if the argument is a list, else nil
-		if len(k.Args) != 1 {
-			return nil, &RuntimeError{Token: k.Keyword, Message: "LIST? operation must have 1 operand"}
+		if reflect.TypeOf(k.Args[0]) == reflect.TypeOf(parser.Symbol{}) {
+			return true, nil
 		}
+		// Otherwise the argument may evaluate to a quoted symbol
 		expr, err := i.evaluate(k.Args[0])
 		if err != nil {
 			return nil, err
 		}
-		return reflect.TypeOf(expr) == reflect.TypeOf(parser.ListExpr{}), nil
-	case scanner.NILQ: // nil? returns true if the argument is nil, else nil
-		if len(k.Args) != 1 {
-			return nil, &RuntimeError{Token: k.Keyword, Message: "NIL? operation must have 1 operand"}
-		}
-		expr, err := i.evaluate(k.Args[0])
-		if err != nil {
-			return nil, err
-		}
-		return expr == nil, nil
-	case scanner.ANDQ: // and? is the logical AND operation
-		if len(k.Args) != 2 {
-			return nil, &RuntimeError{Token: k.Keyword, Message: "AND? operation must have 2 operands"}
-		}
-		left, err := i.evaluate(k.Args[0])
-		if err != nil {
-			return nil, err
-		}
-		right, err := i.evaluate(k.Args[1])
-		if err != nil {
-			return nil, err
-		}
-		return isTruthy(left) && isTruthy(right), nil
-	case scanner.ORQ: // or? is the logical OR operation
+		return symbolQ(i, []interface{}{expr})
+	case scanner.SET: // SET changes the nearest existing binding of the first operand like SET!, and otherwise declares and initializes a global variable
 		if len(k.Args) != 2 {
-			return nil, &RuntimeError{Token: k.Keyword, Message: "OR? operation must have 2 operands"}
+			return nil, &RuntimeError{Token: k.Keyword, Message: "SET operation must have 2 operands"}
 		}
-		left, err := i.evaluate(k.Args[0])
-		if err != nil {
-			return nil, err
+		if reflect.TypeOf(k.Args[0]) != reflect.TypeOf(parser.Symbol{}) {
+			return nil, &RuntimeError{Token: k.Keyword, Message: "SET operation must have a symbol as the first operand"}
 		}
-		right, err := i.evaluate(k.Args[1])
+		value, err := i.evaluate(k.Args[1])
 		if err != nil {
 			return nil, err
 		}
-		result := isTruthy(left) || isTruthy(right)
-		if !result {
-			return nil, nil
-		}
-		return result, nil
-	case scanner.NOTQ: // not? is the logical NOT operator
-		if len(k.Args) != 2 {
-			return nil, &RuntimeError{Token: k.Keyword, Message: "NOT? operation must have 1 operand"}
-		}
-		expr, err := i.evaluate(k.Args[0])
-		if err != nil {
-			return nil, err
+		name := k.Args[0].(parser.Symbol).Name.Lexeme
+		if env := i.environment.resolve(name); env != nil {
+			env.define(name, value)
+		} else {
+			i.globals.define(name, value)
 		}
-		return !isTruthy(expr), nil
-	case scanner.SET: // SET will declare and initialize a global variable with the first operand as the name and the second operand as the value
+		return nil, nil
+	case scanner.SETBANG: // SET! changes the value of the nearest existing binding of the first operand, which may belong to a closure
 		if len(k.Args) != 2 {
-			return nil, &RuntimeError{Token: k.Keyword, Message: "SET operation must have 2 operands"}
+			return nil, &RuntimeError{Token: k.Keyword, Message: "SET! operation must have 2 operands"}
 		}
-		if reflect.TypeOf(k.Args[0]) != reflect.TypeOf(parser.Symbol{}) {
-			return nil, &RuntimeError{Token: k.Keyword, Message: "SET operation must have a symbol as the first operand"}
+		name, ok := k.Args[0].(parser.Symbol)
+		if !ok {
+			return nil, &RuntimeError{Token: k.Keyword, Message: "SET! operation must have a symbol as the first operand"}
 		}
 		value, err := i.evaluate(k.Args[1])
 		if err != nil {
 			return nil, err
 		}
-		i.environment.define(k.Args[0].(parser.Symbol).Name.Lexeme, value)
-		return nil, nil
+		return nil, i.environment.assign(name.Name, value)
 	default:
-		return nil, fmt.Errorf("KEYWORDEXPR not implemented")
+		return nil, &RuntimeError{Token: k.Keyword, Message: "Unexpected keyword '" + k.Keyword.Lexeme + "'."}
 	}
 }
 
+// VisitOperatorExpr evaluates an operator. At the head of a list it is applied to its operands, while
+// anywhere else it evaluates to its native function so that it can be passed around
 func (i *Interpreter) VisitOperatorExpr(o parser.Operator) (interface{}, error) {
-	if len(o.Operands) != 2 { // ensures that binary operators only receive 2 operands
-		return nil, &RuntimeError{Token: o.Operator, Message: "Binary operation must only have two operands"}
-	}
-	left, err := i.evaluate(o.Operands[0])
-	if err != nil {
-		return nil, err
+	operator, ok := operators[o.Operator.Type]
+	if !ok {
+		return nil, &RuntimeError{Token: o.Operator, Message: "Invalid operator"}
 	}
-	right, err := i.evaluate(o.Operands[1])
-	if err != nil {
-		return nil, err
+	if o.Operands == nil {
+		return operator, nil
 	}
 
-	switch o.Operator.Type {
-	case scanner.MINUS:
-		err = checkNumberOperands(o.Operator, left, right)
-		if err != nil {
-			return nil, err
-		}
-		return left.(float64) - right.(float64), nil
-	case scanner.PLUS: // + can be used with a pair of numbers or a pair of strings
-		if reflect.TypeOf(left) == reflect.TypeOf("") && reflect.TypeOf(right) == reflect.TypeOf("") {
-			return left.(string) + right.(string), nil
-		}
-		if reflect.TypeOf(left) == reflect.TypeOf(0.0) && reflect.TypeOf(right) == reflect.TypeOf(0.0) {
-			return left.(float64) + right.(float64), nil
-		}
-		return nil, err
-	case scanner.SLASH:
-		err = checkNumberOperands(o.Operator, left, right)
+	operands := make([]interface{}, len(o.Operands))
+	for j, operand := range o.Operands {
+		value, err := i.evaluate(operand)
 		if err != nil {
 			return nil, err
 		}
-		return left.(float64) / right.(float64), nil
-	case scanner.STAR:
-		err = checkNumberOperands(o.Operator, left, right)
-		if err != nil {
-			return nil, err
-		}
-		return left.(float64) * right.(float64), nil
-	case scanner.GREATER:
-		err = checkNumberOperands(o.Operator, left, right)
-		if err != nil {
-			return nil, err
-		}
-		return left.(float64) > right.(float64), nil
-	case scanner.LESS:
-		err = checkNumberOperands(o.Operator, left, right)
-		if err != nil {
-			return nil, err
-		}
-		return left.(float64) < right.(float64), nil
-	case scanner.EQUAL:
-		return isEqual(left, right), nil
-	default:
-		return nil, &RuntimeError{Token: o.Operator, Message: "Invalid operator"}
+		operands[j] = value
 	}
+
+	result, err := operator.Call(i, operands)
+	return result, withToken(err, o.Operator)
 }
 
 func (i *Interpreter) VisitAtomExpr(a parser.Atom) (interface{}, error) {
@@ -237,6 +153,7 @@ func (i *Interpreter) VisitSymbolExpr(s parser.Symbol) (interface{}, error) {
 }
 
 func (i *Interpreter) VisitCallExpr(c parser.Call) (interface{}, error) {
+	tail := i.tail
 	callee, err := i.evaluate(c.Callee)
 	if err != nil {
 		return nil, err
@@ -254,11 +171,21 @@ func (i *Interpreter) VisitCallExpr(c parser.Call) (interface{}, error) {
 	if !ok {
 		return nil, &RuntimeError{Token: c.Token, Message: "Can only call functions."}
 	}
-	if len(arguments) != function.Arity() {
+	if function.Arity() >= 0 && len(arguments) != function.Arity() {
 		return nil, &RuntimeError{Token: c.Token, Message: "Expected " + fmt.Sprint(function.Arity()) + " arguments but got " + fmt.Sprint(len(arguments)) + "."}
 	}
 
-	return function.Call(i, arguments)
+	if lispFunction, ok := function.(LispFunction); ok {
+		// Calls in tail position are handed back to the trampoline of the function being returned from
+		if tail {
+			return &tailCall{function: lispFunction, arguments: arguments}, nil
+		}
+		result, err := lispFunction.call(i, c.Token, arguments)
+		return result, withToken(err, c.Token)
+	}
+
+	result, err := function.Call(i, arguments)
+	return result, withToken(err, c.Token)
 }
 
 func (i *Interpreter) VisitFuncDefinitionExpr(f parser.FuncDefinition) (interface{}, error) {
@@ -266,3 +193,162 @@ func (i *Interpreter) VisitFuncDefinitionExpr(f parser.FuncDefinition) (interfac
 	i.environment.define(f.Name.Lexeme, function)
 	return nil, nil
 }
+
+// VisitMacroDefinitionExpr binds a macro, which the forms parsed after it can then use
+func (i *Interpreter) VisitMacroDefinitionExpr(m parser.MacroDefinition) (interface{}, error) {
+	i.environment.define(m.Name.Lexeme, &Macro{Declaration: m, Closure: i.environment})
+	return nil, nil
+}
+
+// VisitVarDefinitionExpr binds a variable. define creates a new binding in the current scope, while defvar
+// and defparameter define globals from anywhere. defvar leaves a global that is already bound untouched
+// without evaluating its value, where defparameter always sets it
+func (i *Interpreter) VisitVarDefinitionExpr(d parser.VarDefinition) (interface{}, error) {
+	env := i.environment
+	if d.Keyword.Type != scanner.DEFINE {
+		env = i.globals
+	}
+	if d.Keyword.Type == scanner.DEFVAR && i.globals.resolve(d.Name.Lexeme) == i.globals {
+		return nil, nil
+	}
+
+	value, err := i.evaluate(d.Value)
+	if err != nil {
+		return nil, err
+	}
+	env.define(d.Name.Lexeme, value)
+	return nil, nil
+}
+
+// VisitTryExpr evaluates the body of a try, and if it raises a RuntimeError, evaluates the catch clause with
+// the error bound to its variable. The finally clause is evaluated however the rest of the try ends, and
+// an error it raises takes the place of the try's result. Exits are not errors, so they are never caught
+func (i *Interpreter) VisitTryExpr(t parser.Try) (interface{}, error) {
+	// Nothing in a try is in tail position, since the try has to outlive any calls made inside it
+	if t.Catch != nil {
+		i.catching++
+	}
+	result, err := i.evaluateSequence(t.Body, false)
+	if t.Catch != nil {
+		i.catching--
+	}
+	if runtimeErr, ok := err.(*RuntimeError); ok && t.Catch != nil {
+		env := NewEnvironmentWithEnclosing(i.environment)
+		env.define(t.Catch.Name.Lexeme, runtimeErr.value())
+		result, err = i.evaluateFunction(t.Catch.Body, env, false)
+	}
+
+	if t.Finally != nil {
+		if _, finallyErr := i.evaluateSequence(t.Finally, false); finallyErr != nil {
+			return nil, finallyErr
+		}
+	}
+	return result, err
+}
+
+// VisitHandlerBindExpr evaluates the body of a handler-bind with its handlers in effect. The body is not in
+// tail position, since the handlers have to stay in effect until it is done
+func (i *Interpreter) VisitHandlerBindExpr(h parser.HandlerBind) (interface{}, error) {
+	handlers := make([]handler, len(h.Handlers))
+	for j, clause := range h.Handlers {
+		value, err := i.evaluate(clause.Handler)
+		if err != nil {
+			return nil, err
+		}
+		function, ok := value.(LispCallable)
+		if !ok || (function.Arity() >= 0 && function.Arity() != 1) {
+			return nil, &RuntimeError{Token: clause.Kind, Message: "Handler must be a function of 1 argument"}
+		}
+		handlers[j] = handler{kind: Symbol{Name: clause.Kind.Lexeme}, function: function}
+	}
+
+	i.handlers = append(i.handlers, handlers)
+	result, err := i.evaluateSequence(h.Body, false)
+	i.handlers = i.handlers[:len(i.handlers)-1]
+	return result, err
+}
+
+// VisitRestartCaseExpr evaluates an expression with the restarts of a restart-case available. When one of them
+// is invoked, the expression is abandoned and the restart's body is evaluated with its arguments bound instead
+func (i *Interpreter) VisitRestartCaseExpr(r parser.RestartCase) (interface{}, error) {
+	tail := i.tail
+	point := &restartPoint{restarts: r.Restarts}
+
+	i.restarts = append(i.restarts, point)
+	result, err := i.evaluate(r.Expr)
+	i.restarts = i.restarts[:len(i.restarts)-1]
+
+	transfer, ok := err.(*restartTransfer)
+	if !ok || transfer.point != point {
+		return result, err
+	}
+
+	restart := r.Restarts[transfer.restart]
+	if len(transfer.arguments) != len(restart.Params) {
+		return nil, &RuntimeError{Token: restart.Name, Message: "Expected " + fmt.Sprint(len(restart.Params)) + " arguments but got " + fmt.Sprint(len(transfer.arguments)) + "."}
+	}
+	env := NewEnvironmentWithEnclosing(i.environment)
+	for j, param := range restart.Params {
+		env.define(param.Lexeme, transfer.arguments[j])
+	}
+	return i.evaluateFunction(restart.Body, env, tail)
+}
+
+func (i *Interpreter) VisitQuoteExpr(q parser.Quote) (interface{}, error) {
+	return datumValue(q.Datum), nil
+}
+
+func (i *Interpreter) VisitQuasiquoteExpr(q parser.Quasiquote) (interface{}, error) {
+	return i.quasiquote(q.Datum)
+}
+
+// VisitUnquoteExpr is only reached for an unquote outside of any quasiquote template
+func (i *Interpreter) VisitUnquoteExpr(u parser.Unquote) (interface{}, error) {
+	return nil, &RuntimeError{Token: u.Keyword, Message: "UNQUOTE must appear inside of a quasiquote"}
+}
+
+// VisitLambdaExpr creates a function value that closes over the current environment
+func (i *Interpreter) VisitLambdaExpr(l parser.Lambda) (interface{}, error) {
+	declaration := parser.FuncDefinition{Name: l.Keyword, Params: l.Params, Body: l.Body, Span: l.Span}
+	return LispFunction{Declaration: declaration, Closure: i.environment, IsInitializer: false}, nil
+}
+
+// VisitLetExpr evaluates the body of a let in a new environment holding its bindings.
+// let evaluates every value in the enclosing scope, let* makes each binding visible to the
+// ones after it, and letrec makes every binding visible to all values so local functions can recurse
+func (i *Interpreter) VisitLetExpr(l parser.Let) (interface{}, error) {
+	tail := i.tail
+	env := NewEnvironmentWithEnclosing(i.environment)
+
+	switch l.Keyword.Type {
+	case scanner.LET:
+		for _, binding := range l.Bindings {
+			value, err := i.evaluate(binding.Value)
+			if err != nil {
+				return nil, err
+			}
+			env.define(binding.Name.Lexeme, value)
+		}
+	case scanner.LETSTAR:
+		for _, binding := range l.Bindings {
+			value, err := i.evaluateFunction([]parser.Expression{binding.Value}, env, false)
+			if err != nil {
+				return nil, err
+			}
+			env.define(binding.Name.Lexeme, value)
+		}
+	case scanner.LETREC:
+		for _, binding := range l.Bindings {
+			env.define(binding.Name.Lexeme, nil)
+		}
+		for _, binding := range l.Bindings {
+			value, err := i.evaluateFunction([]parser.Expression{binding.Value}, env, false)
+			if err != nil {
+				return nil, err
+			}
+			env.define(binding.Name.Lexeme, value)
+		}
+	}
+
+	return i.evaluateFunction(l.Body, env, tail)
+}
@@ -4,32 +4,48 @@ import (
 	"golisp/pkg/scanner"
 )
 
-// ParseError reports an error through the scanner. Probably should've used the same
-// error handling from the interpreter, but this was already built
-func ParseError(t scanner.Token, message string) {
+// error records a diagnostic for the passed token on the parser, to be returned from Parse
+func (p *Parser) error(t scanner.Token, message string) {
+	where := " at '" + t.Lexeme + "'"
 	if t.Type == scanner.EOF {
-		scanner.Report(t.Line, " at end", message)
-	} else {
-		scanner.Report(t.Line, " at '"+t.Lexeme+"'", message)
+		where = " at end"
 	}
+	diagnostic := scanner.Diagnostic{
+		Phase:   scanner.ParsePhase,
+		Span:    t.Span(),
+		Where:   where,
+		Message: message,
+	}
+
+	// Recovering from an unclosed form can parse the same tokens twice, but each error is only reported once
+	if n := len(p.Diagnostics); n > 0 && p.Diagnostics[n-1] == diagnostic {
+		return
+	}
+	p.Diagnostics = append(p.Diagnostics, diagnostic)
 }
 
-// synchronize will advance the parser until it reaches the end of the current expression
-// to get back to a known state after an error is encountered
-func (p *Parser) synchronize() {
-	p.advance()
+// synchronize skips past the rest of the top-level form beginning at the token index start, to get back
+// to a known state after an error is encountered. A form that is never closed ends early at the next
+// opening parenthesis in the first column, since that almost always begins a new top-level form
+func (p *Parser) synchronize(start int) {
+	p.Curr = start
+	depth := 0
 
 	for !p.isAtEnd() {
-		if p.previous().Type == scanner.SEMICOLON {
-			return
+		switch p.advance().Type {
+		case scanner.LEFT_PAREN:
+			depth++
+		case scanner.RIGHT_PAREN:
+			depth--
+		case scanner.APOSTROPHE, scanner.BACKQUOTE, scanner.COMMA, scanner.COMMA_AT:
+			continue // the quoted form that follows belongs to this one
 		}
 
-		switch p.peek().Type {
-		case scanner.DEFINE:
-		case scanner.SET:
-		case scanner.COND:
+		if depth <= 0 {
+			return
+		}
+		if p.check(scanner.LEFT_PAREN) && p.peek().Column == 1 {
+			return
 		}
-
-		p.advance()
 	}
 }
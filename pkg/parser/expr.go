@@ -10,6 +10,7 @@ import (
 type Expression interface {
 	Accept(v ExprVisitor) (interface{}, error)
 	String() string
+	Position() scanner.Span // the region of source the expression was parsed from
 }
 
 // Atom Interface
@@ -18,6 +19,7 @@ type Expression interface {
 type Atom struct {
 	Value interface{}
 	Type  scanner.TokenType
+	Span  scanner.Span
 }
 
 // Accept is a method that visits the Atom expression and returns the result
@@ -29,6 +31,10 @@ func (l Atom) String() string {
 	return stringify(l.Value)
 }
 
+func (l Atom) Position() scanner.Span {
+	return l.Span
+}
+
 type Operator struct {
 	Operator scanner.Token
 	Operands []Expression
@@ -42,10 +48,16 @@ func (o Operator) String() string {
 	return o.Operator.Lexeme
 }
 
-// S-Expression
+func (o Operator) Position() scanner.Span {
+	return o.Operator.Span()
+}
+
+// S-Expression. Dotted holds the final element of an improper list such as (1 2 . 3), and is nil otherwise
 type ListExpr struct {
-	Head Expression
-	Tail []Expression
+	Head   Expression
+	Tail   []Expression
+	Dotted Expression
+	Span   scanner.Span
 }
 
 func (l ListExpr) Accept(v ExprVisitor) (interface{}, error) {
@@ -60,10 +72,17 @@ func (l ListExpr) String() string {
 			output += " " + expr.String()
 		}
 	}
+	if l.Dotted != nil {
+		output += " . " + l.Dotted.String()
+	}
 	output += ")"
 	return output
 }
 
+func (l ListExpr) Position() scanner.Span {
+	return l.Span
+}
+
 type Keyword struct {
 	Keyword scanner.Token
 	Args    []Expression
@@ -77,6 +96,10 @@ func (k Keyword) String() string {
 	return scanner.KeywordsReverse[k.Keyword.Type]
 }
 
+func (k Keyword) Position() scanner.Span {
+	return k.Keyword.Span()
+}
+
 // Variable
 
 // Variable is a struct that implements the Expression interface
@@ -93,10 +116,15 @@ func (s Symbol) String() string {
 	return s.Name.Lexeme
 }
 
+func (s Symbol) Position() scanner.Span {
+	return s.Name.Span()
+}
+
 type FuncDefinition struct {
 	Name   scanner.Token
 	Params []scanner.Token
-	Body   Expression
+	Body   []Expression
+	Span   scanner.Span
 }
 
 func (f FuncDefinition) Accept(v ExprVisitor) (interface{}, error) {
@@ -104,7 +132,218 @@ func (f FuncDefinition) Accept(v ExprVisitor) (interface{}, error) {
 }
 
 func (f FuncDefinition) String() string {
-	return "Define " + f.Name.Lexeme + " " + stringify(f.Params) + " " + f.Body.String()
+	return "Define " + f.Name.Lexeme + " " + stringify(f.Params) + " " + stringifyBody(f.Body)
+}
+
+func (f FuncDefinition) Position() scanner.Span {
+	return f.Span
+}
+
+// VarDefinition binds a variable to a value. Keyword tells define, which binds it in the current scope,
+// apart from defvar and defparameter, which bind globals
+type VarDefinition struct {
+	Keyword scanner.Token
+	Name    scanner.Token
+	Value   Expression
+	Span    scanner.Span
+}
+
+func (d VarDefinition) Accept(v ExprVisitor) (interface{}, error) {
+	return v.VisitVarDefinitionExpr(d)
+}
+
+func (d VarDefinition) String() string {
+	return scanner.KeywordsReverse[d.Keyword.Type] + " " + d.Name.Lexeme + " " + d.Value.String()
+}
+
+func (d VarDefinition) Position() scanner.Span {
+	return d.Span
+}
+
+// MacroDefinition defines a macro, a function from the unevaluated forms of a call to the form that replaces
+// it. Rest is the parameter after &rest, which collects any forms beyond Params as a list
+type MacroDefinition struct {
+	Name   scanner.Token
+	Params []scanner.Token
+	Rest   *scanner.Token
+	Body   []Expression
+	Span   scanner.Span
+}
+
+func (m MacroDefinition) Accept(v ExprVisitor) (interface{}, error) {
+	return v.VisitMacroDefinitionExpr(m)
+}
+
+func (m MacroDefinition) String() string {
+	params := stringify(m.Params)
+	if m.Rest != nil {
+		params += " &rest " + m.Rest.Lexeme
+	}
+	return "Defmacro " + m.Name.Lexeme + " " + params + " " + stringifyBody(m.Body)
+}
+
+func (m MacroDefinition) Position() scanner.Span {
+	return m.Span
+}
+
+// Lambda
+
+// Lambda is an anonymous function, which evaluates to a function value rather than binding a name
+type Lambda struct {
+	Keyword scanner.Token
+	Params  []scanner.Token
+	Body    []Expression
+	Span    scanner.Span
+}
+
+func (l Lambda) Accept(v ExprVisitor) (interface{}, error) {
+	return v.VisitLambdaExpr(l)
+}
+
+func (l Lambda) String() string {
+	return "Lambda " + stringify(l.Params) + " " + stringifyBody(l.Body)
+}
+
+func (l Lambda) Position() scanner.Span {
+	return l.Span
+}
+
+// Let
+
+// Binding pairs a local variable name with the expression that initializes it
+type Binding struct {
+	Name  scanner.Token
+	Value Expression
+}
+
+// Let evaluates its body in a new scope holding its bindings. Keyword tells let, let* and letrec apart
+type Let struct {
+	Keyword  scanner.Token
+	Bindings []Binding
+	Body     []Expression
+	Span     scanner.Span
+}
+
+func (l Let) Accept(v ExprVisitor) (interface{}, error) {
+	return v.VisitLetExpr(l)
+}
+
+func (l Let) String() string {
+	output := scanner.KeywordsReverse[l.Keyword.Type] + " ("
+	for j, binding := range l.Bindings {
+		if j > 0 {
+			output += " "
+		}
+		output += "(" + binding.Name.Lexeme + " " + binding.Value.String() + ")"
+	}
+	return output + ") " + stringifyBody(l.Body)
+}
+
+func (l Let) Position() scanner.Span {
+	return l.Span
+}
+
+// Try
+
+// Try evaluates its body, handing any error raised in it to the Catch clause if there is one,
+// and then always evaluates Finally, even when the body or the handler raised an error
+type Try struct {
+	Keyword scanner.Token
+	Body    []Expression
+	Catch   *CatchClause
+	Finally []Expression
+	Span    scanner.Span
+}
+
+// CatchClause binds the error caught by a Try to Name while evaluating Body
+type CatchClause struct {
+	Name scanner.Token
+	Body []Expression
+}
+
+func (t Try) Accept(v ExprVisitor) (interface{}, error) {
+	return v.VisitTryExpr(t)
+}
+
+func (t Try) String() string {
+	output := "try " + stringifyBody(t.Body)
+	if t.Catch != nil {
+		output += " (catch (" + t.Catch.Name.Lexeme + ") " + stringifyBody(t.Catch.Body) + ")"
+	}
+	if t.Finally != nil {
+		output += " (finally " + stringifyBody(t.Finally) + ")"
+	}
+	return output
+}
+
+func (t Try) Position() scanner.Span {
+	return t.Span
+}
+
+// HandlerBind evaluates its body with handlers for the conditions signalled inside of it. A handler is
+// called at the point of the signal, without unwinding, so it can invoke a restart or decline by returning
+type HandlerBind struct {
+	Keyword  scanner.Token
+	Handlers []HandlerClause
+	Body     []Expression
+	Span     scanner.Span
+}
+
+// HandlerClause pairs the kind of condition a handler is for with the expression giving the handler function
+type HandlerClause struct {
+	Kind    scanner.Token
+	Handler Expression
+}
+
+func (h HandlerBind) Accept(v ExprVisitor) (interface{}, error) {
+	return v.VisitHandlerBindExpr(h)
+}
+
+func (h HandlerBind) String() string {
+	output := "handler-bind ("
+	for j, clause := range h.Handlers {
+		if j > 0 {
+			output += " "
+		}
+		output += "(" + clause.Kind.Lexeme + " " + clause.Handler.String() + ")"
+	}
+	return output + ") " + stringifyBody(h.Body)
+}
+
+func (h HandlerBind) Position() scanner.Span {
+	return h.Span
+}
+
+// RestartCase evaluates Expr with its restarts available. Invoking one of them abandons Expr, and the
+// value of the restart's body becomes the value of the RestartCase
+type RestartCase struct {
+	Keyword  scanner.Token
+	Expr     Expression
+	Restarts []RestartClause
+	Span     scanner.Span
+}
+
+// RestartClause is a restart named Name, which binds its Params to the arguments it is invoked with
+type RestartClause struct {
+	Name   scanner.Token
+	Params []scanner.Token
+	Body   []Expression
+}
+
+func (r RestartCase) Accept(v ExprVisitor) (interface{}, error) {
+	return v.VisitRestartCaseExpr(r)
+}
+
+func (r RestartCase) String() string {
+	output := "restart-case " + r.Expr.String()
+	for _, clause := range r.Restarts {
+		output += " (" + clause.Name.Lexeme + " " + stringify(clause.Params) + " " + stringifyBody(clause.Body) + ")"
+	}
+	return output
+}
+
+func (r RestartCase) Position() scanner.Span {
+	return r.Span
 }
 
 // Call
@@ -114,6 +353,7 @@ type Call struct {
 	Callee   Expression
 	Token    scanner.Token
 	ArgsList []Expression
+	Span     scanner.Span
 }
 
 // Accept is a method that returns a string representation of the expression
@@ -125,8 +365,76 @@ func (c Call) String() string {
 	return c.Callee.String()
 }
 
+func (c Call) Position() scanner.Span {
+	return c.Span
+}
+
 type Function struct {
 	Name   scanner.Token
 	Params []scanner.Token
 	Body   Expression
 }
+
+// Quote
+
+// Quote holds a datum that evaluates to itself as data, e.g. 'x or (quote (1 2 3))
+type Quote struct {
+	Keyword scanner.Token
+	Datum   Expression
+	Span    scanner.Span
+}
+
+func (q Quote) Accept(v ExprVisitor) (interface{}, error) {
+	return v.VisitQuoteExpr(q)
+}
+
+func (q Quote) String() string {
+	return "'" + q.Datum.String()
+}
+
+func (q Quote) Position() scanner.Span {
+	return q.Span
+}
+
+// Quasiquote holds a template that evaluates to data, with any Unquote inside of it evaluated as code
+type Quasiquote struct {
+	Keyword scanner.Token
+	Datum   Expression
+	Span    scanner.Span
+}
+
+func (q Quasiquote) Accept(v ExprVisitor) (interface{}, error) {
+	return v.VisitQuasiquoteExpr(q)
+}
+
+func (q Quasiquote) String() string {
+	return "`" + q.Datum.String()
+}
+
+func (q Quasiquote) Position() scanner.Span {
+	return q.Span
+}
+
+// Unquote is an expression inside a quasiquote template that is evaluated, either with ,x or
+// with ,@x which splices the resulting list into the surrounding one
+type Unquote struct {
+	Keyword  scanner.Token
+	Expr     Expression
+	Splicing bool
+	Span     scanner.Span
+}
+
+func (u Unquote) Accept(v ExprVisitor) (interface{}, error) {
+	return v.VisitUnquoteExpr(u)
+}
+
+func (u Unquote) String() string {
+	if u.Splicing {
+		return ",@" + u.Expr.String()
+	}
+	return "," + u.Expr.String()
+}
+
+func (u Unquote) Position() scanner.Span {
+	return u.Span
+}
@@ -5,6 +5,7 @@ import (
 	"golisp/pkg/scanner"
 	// "strconv"
 	"fmt"
+	"math/big"
 )
 
 func (p *Parser) expr() (Expression, error) {
@@ -13,26 +14,99 @@ func (p *Parser) expr() (Expression, error) {
 
 func (p *Parser) list() (Expression, error) {
 	if p.match(scanner.LEFT_PAREN) {
+		start := p.previous()
+		topLevel := p.Curr-1 == p.top
+
+		// (quote x) and (quasiquote x) are the long forms of 'x and `x
+		if p.match(scanner.QUOTE, scanner.QUASIQUOTE) {
+			return p.quotation(start, p.previous())
+		}
+
+		// () is the empty list, which is nil
+		if p.match(scanner.RIGHT_PAREN) {
+			return Atom{Value: nil, Span: p.spanFrom(start)}, nil
+		}
+
 		// handle parsing of list
 		head, err := p.expr() // First element is the operator or function
 		if err != nil {
 			return nil, err
 		}
 
+		// A call to a macro is replaced by what the macro expands it into
+		if name, ok := head.(Symbol); ok && p.Macros != nil && p.Macros.IsMacro(name.Name.Lexeme) {
+			return p.macroCall(start, name, topLevel)
+		}
+
 		// If Head is a symbol, evaluate and return function call
 		if funcName, ok := head.(Symbol); ok {
-			return p.functionCall(funcName)
+			return p.functionCall(start, funcName, funcName.Name)
+		}
+
+		// Heads that evaluate to functions, like ((lambda (x) x) 1) or ((makeAdder 1) 2), are also calls
+		switch callee := head.(type) {
+		case Lambda:
+			return p.functionCall(start, callee, callee.Keyword)
+		case Call:
+			return p.functionCall(start, callee, callee.Token)
 		}
 
-		// If Head is 'define', we expect a function definition and return it
-		if kw, ok := head.(Keyword); ok && kw.Keyword.Type == scanner.DEFINE {
-			return p.functionDefinition()
+		// If Head is 'define', 'defvar' or 'defparameter', we expect a function or variable definition and return it
+		if kw, ok := head.(Keyword); ok && (kw.Keyword.Type == scanner.DEFINE || kw.Keyword.Type == scanner.DEFVAR || kw.Keyword.Type == scanner.DEFPARAMETER) {
+			return p.definition(start, kw.Keyword)
+		}
+
+		// If Head is 'defmacro', we expect a macro definition, which has to be a top-level form so that the
+		// forms parsed after it can use the macro
+		if kw, ok := head.(Keyword); ok && kw.Keyword.Type == scanner.DEFMACRO {
+			if !topLevel {
+				p.error(kw.Keyword, "Defmacro outside of the top level.")
+				return nil, errors.New("defmacro outside of the top level")
+			}
+			return p.macroDefinition(start)
+		}
+
+		// If Head is 'cond' and its branches are in clauses, we expect clauses holding several expressions each
+		if kw, ok := head.(Keyword); ok && kw.Keyword.Type == scanner.COND && p.isCondClause() {
+			return p.condClauses(start, kw)
+		}
+
+		// If Head is 'lambda', we expect an anonymous function and return it
+		if kw, ok := head.(Keyword); ok && kw.Keyword.Type == scanner.LAMBDA {
+			return p.lambda(start, kw.Keyword)
+		}
+
+		// If Head is 'let', 'let*' or 'letrec', we expect local bindings followed by a body
+		if kw, ok := head.(Keyword); ok && (kw.Keyword.Type == scanner.LET || kw.Keyword.Type == scanner.LETSTAR || kw.Keyword.Type == scanner.LETREC) {
+			return p.let(start, kw.Keyword)
+		}
+
+		// If Head is 'try', we expect a body followed by catch and finally clauses
+		if kw, ok := head.(Keyword); ok && kw.Keyword.Type == scanner.TRY {
+			return p.try(start, kw.Keyword)
+		}
+
+		// If Head is 'handler-bind' or 'restart-case', we expect the clauses of the condition system
+		if kw, ok := head.(Keyword); ok && kw.Keyword.Type == scanner.HANDLER_BIND {
+			return p.handlerBind(start, kw.Keyword)
+		}
+		if kw, ok := head.(Keyword); ok && kw.Keyword.Type == scanner.RESTART_CASE {
+			return p.restartCase(start, kw.Keyword)
 		}
 
 		// If the list isn't a function call or definition, it is a normal list
 		// so we will simply evaluate each element and build up the tail
 		var tail []Expression
+		var dotted Expression
 		for !p.check(scanner.RIGHT_PAREN) && !p.isAtEnd() {
+			// A dot introduces the final element of an improper list, e.g. (1 2 . 3)
+			if p.match(scanner.DOT) {
+				dotted, err = p.expr()
+				if err != nil {
+					return nil, err
+				}
+				break
+			}
 			expr, err := p.expr() // Parse each operand
 			if err != nil {
 				return nil, err
@@ -46,14 +120,14 @@ func (p *Parser) list() (Expression, error) {
 			return nil, err
 		}
 
-		return ListExpr{Head: head, Tail: tail}, nil
+		return ListExpr{Head: head, Tail: tail, Dotted: dotted, Span: p.spanFrom(start)}, nil
 	}
 
 	// If it's not a list, it might be an atom or other type of expression
 	return p.atom()
 }
 
-func (p *Parser) functionCall(funcName Symbol) (Expression, error) {
+func (p *Parser) functionCall(start scanner.Token, callee Expression, token scanner.Token) (Expression, error) {
 	// Expecting a list of parameters
 	params, err := p.callParamList()
 	if err != nil {
@@ -65,7 +139,7 @@ func (p *Parser) functionCall(funcName Symbol) (Expression, error) {
 		return nil, err
 	}
 
-	return Call{Callee: funcName, Token: funcName.Name, ArgsList: params}, nil
+	return Call{Callee: callee, Token: token, ArgsList: params, Span: p.spanFrom(start)}, nil
 }
 
 func (p *Parser) callParamList() ([]Expression, error) {
@@ -84,20 +158,82 @@ func (p *Parser) callParamList() ([]Expression, error) {
 
 }
 
-func (p *Parser) functionDefinition() (Expression, error) {
-	functionName, err := p.consume(scanner.SYMBOL, "Expect function name.")
+// definition parses (define name (params) body...) and (define (name params...) body...) as function
+// definitions, and (define name value), (defvar name value) and (defparameter name value) as variable ones
+func (p *Parser) definition(start scanner.Token, keyword scanner.Token) (Expression, error) {
+	if keyword.Type == scanner.DEFINE && !p.isVariableDefinition() {
+		return p.functionDefinition(start)
+	}
+
+	name, err := p.consume(scanner.SYMBOL, "Expect variable name.")
 	if err != nil {
 		return nil, err
 	}
 
-	// Expecting a list of parameters
-	params, err := p.paramList()
+	value, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.consume(scanner.RIGHT_PAREN, "Expect ')' after variable definition.")
+	if err != nil {
+		return nil, err
+	}
+
+	return VarDefinition{Keyword: keyword, Name: name, Value: value, Span: p.spanFrom(start)}, nil
+}
+
+// isVariableDefinition looks ahead to tell (define name value) apart from (define name (params) body...),
+// where the parenthesized list after the name is followed by a body rather than closing the definition
+func (p *Parser) isVariableDefinition() bool {
+	if p.peek().Type != scanner.SYMBOL {
+		return false
+	}
+	if p.Tokens[p.Curr+1].Type != scanner.LEFT_PAREN {
+		return true
+	}
+
+	depth := 0
+	for j := p.Curr + 1; j < len(p.Tokens) && p.Tokens[j].Type != scanner.EOF; j++ {
+		switch p.Tokens[j].Type {
+		case scanner.LEFT_PAREN:
+			depth++
+		case scanner.RIGHT_PAREN:
+			depth--
+		}
+		if depth == 0 {
+			return p.Tokens[j+1].Type == scanner.RIGHT_PAREN
+		}
+	}
+	return false
+}
+
+func (p *Parser) functionDefinition(start scanner.Token) (Expression, error) {
+	var functionName scanner.Token
+	var params []scanner.Token
+	var err error
+
+	if p.match(scanner.LEFT_PAREN) {
+		// (define (name params...) body...) puts the name in front of the parameters
+		functionName, err = p.consume(scanner.SYMBOL, "Expect function name.")
+		if err != nil {
+			return nil, err
+		}
+		params, err = p.params()
+	} else {
+		functionName, err = p.consume(scanner.SYMBOL, "Expect function name.")
+		if err != nil {
+			return nil, err
+		}
+		// Expecting a list of parameters
+		params, err = p.paramList()
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	// The function body is an expression
-	body, err := p.expr()
+	// The function body is one or more expressions
+	body, err := p.body()
 	if err != nil {
 		return nil, err
 	}
@@ -108,16 +244,424 @@ func (p *Parser) functionDefinition() (Expression, error) {
 	}
 
 	// Function definition is returned but will not be printed to terminal like other expressions
-	return FuncDefinition{Name: functionName, Params: params, Body: body}, nil
+	return FuncDefinition{Name: functionName, Params: params, Body: body, Span: p.spanFrom(start)}, nil
 }
 
-func (p *Parser) paramList() ([]scanner.Token, error) {
-	var params []scanner.Token
+// macroDefinition parses (defmacro name (params... &rest rest) body...)
+func (p *Parser) macroDefinition(start scanner.Token) (Expression, error) {
+	name, err := p.consume(scanner.SYMBOL, "Expect macro name.")
+	if err != nil {
+		return nil, err
+	}
+	params, err := p.paramList()
+	if err != nil {
+		return nil, err
+	}
+
+	// &rest may only come before the last parameter
+	var rest *scanner.Token
+	for j, param := range params {
+		if param.Lexeme != "&rest" {
+			continue
+		}
+		if j != len(params)-2 {
+			p.error(param, "Expect a single parameter after &rest.")
+			return nil, errors.New("expect a single parameter after &rest")
+		}
+		restParam := params[j+1]
+		rest, params = &restParam, params[:j]
+		break
+	}
+
+	body, err := p.body()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.consume(scanner.RIGHT_PAREN, "Expect ')' after macro definition.")
+	if err != nil {
+		return nil, err
+	}
+
+	return MacroDefinition{Name: name, Params: params, Rest: rest, Body: body, Span: p.spanFrom(start)}, nil
+}
+
+// macroCall reads the forms of a call to a macro as data, and parses the form the macro expands the call
+// into in its place, which is a top-level form if the call was. Errors in the expansion are reported at the call
+func (p *Parser) macroCall(start scanner.Token, name Symbol, topLevel bool) (Expression, error) {
+	var forms []Expression
+	p.literal = true
+	for !p.check(scanner.RIGHT_PAREN) && !p.isAtEnd() {
+		form, err := p.datum(0)
+		if err != nil {
+			p.literal = false
+			return nil, err
+		}
+		forms = append(forms, form)
+	}
+	p.literal = false
+
+	_, err := p.consume(scanner.RIGHT_PAREN, "Expect ')' after macro call.")
+	if err != nil {
+		return nil, err
+	}
+
+	if p.expansions >= maxExpansionDepth {
+		p.error(name.Name, fmt.Sprintf("Error expanding macro: more than %d nested expansions.", maxExpansionDepth))
+		return nil, errors.New("too many nested macro expansions")
+	}
+
+	tokens, err := p.Macros.ExpandMacro(ListExpr{Head: name, Tail: forms, Span: p.spanFrom(start)})
+	if err != nil {
+		p.error(name.Name, "Error expanding macro: "+err.Error())
+		return nil, err
+	}
+
+	expansion := Parser{Tokens: tokens, Macros: p.Macros, top: -1, expansions: p.expansions + 1}
+	if topLevel {
+		expansion.top = 0
+	}
+	expr, err := expansion.expr()
+	p.Diagnostics = append(p.Diagnostics, expansion.Diagnostics...)
+	return expr, err
+}
+
+// body parses the expressions making up the body of a function or let, up to the closing parenthesis.
+// They are evaluated in order and the value of the last one is returned
+func (p *Parser) body() ([]Expression, error) {
+	var body []Expression
+	for !p.check(scanner.RIGHT_PAREN) && !p.isAtEnd() {
+		expr, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		body = append(body, expr)
+	}
+
+	if len(body) == 0 {
+		p.error(p.peek(), "Expect body expression.")
+		return nil, errors.New("expect body expression")
+	}
+	return body, nil
+}
+
+// isCondClause looks ahead to tell (cond (test e1 e2...) ...) apart from the flat (cond test result ...).
+// A cond is read as clauses when one of its arguments is a list of at least two forms starting with
+// something that cannot be called, like a literal or another list, or when every argument is a list of
+// at least two forms and there are too many of them to pair up into tests and results
+func (p *Parser) isCondClause() bool {
+	args, clauses, uncallable := 0, 0, false
+	for j := p.Curr; p.Tokens[j].Type != scanner.RIGHT_PAREN; args++ {
+		if p.Tokens[j].Type == scanner.EOF {
+			return false
+		}
+		if p.Tokens[j].Type != scanner.LEFT_PAREN {
+			j = p.formEnd(j)
+			continue
+		}
+
+		head := p.Tokens[j+1].Type
+		forms := 0
+		for j++; p.Tokens[j].Type != scanner.RIGHT_PAREN; forms++ {
+			if p.Tokens[j].Type == scanner.EOF {
+				return false
+			}
+			j = p.formEnd(j)
+		}
+		j++
+		if forms < 2 {
+			continue
+		}
+
+		clauses++
+		switch head {
+		case scanner.LEFT_PAREN, scanner.TRUE, scanner.NIL, scanner.FALSE, scanner.NUMBER, scanner.STRING:
+			uncallable = true
+		}
+	}
+	return uncallable || (clauses == args && args%2 == 1)
+}
+
+// formEnd returns the index of the token just past the form starting at the token index j, including
+// any quote marks in front of it
+func (p *Parser) formEnd(j int) int {
+	depth := 0
+	for ; p.Tokens[j].Type != scanner.EOF; j++ {
+		switch p.Tokens[j].Type {
+		case scanner.LEFT_PAREN:
+			depth++
+		case scanner.RIGHT_PAREN:
+			depth--
+		case scanner.APOSTROPHE, scanner.BACKQUOTE, scanner.COMMA, scanner.COMMA_AT:
+			continue
+		}
+		if depth <= 0 {
+			return j + 1
+		}
+	}
+	return j
+}
+
+// condClauses parses a cond made of clauses like (test e1 e2...), whose expressions are evaluated in order
+// when the test is true. Each clause becomes a test and a begin in the flat form
+func (p *Parser) condClauses(start scanner.Token, keyword Keyword) (Expression, error) {
+	begin := keyword.Keyword
+	begin.Type = scanner.BEGIN
+	begin.Lexeme = scanner.KeywordsReverse[scanner.BEGIN]
+
+	var args []Expression
+	for p.match(scanner.LEFT_PAREN) {
+		clauseStart := p.previous()
+		test, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		body, err := p.body()
+		if err != nil {
+			return nil, err
+		}
+		_, err = p.consume(scanner.RIGHT_PAREN, "Expect ')' after cond clause.")
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, test, ListExpr{Head: Keyword{Keyword: begin}, Tail: body, Span: p.spanFrom(clauseStart)})
+	}
+
+	_, err := p.consume(scanner.RIGHT_PAREN, "Expect '(' before cond clause.")
+	if err != nil {
+		return nil, err
+	}
+	return ListExpr{Head: keyword, Tail: args, Span: p.spanFrom(start)}, nil
+}
+
+// lambda parses an anonymous function of the form (lambda (params) body...)
+func (p *Parser) lambda(start scanner.Token, keyword scanner.Token) (Expression, error) {
+	params, err := p.paramList()
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := p.body()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.consume(scanner.RIGHT_PAREN, "Expect ')' after lambda body.")
+	if err != nil {
+		return nil, err
+	}
+
+	return Lambda{Keyword: keyword, Params: params, Body: body, Span: p.spanFrom(start)}, nil
+}
+
+// let parses local bindings of the form (let ((name value)...) body...)
+func (p *Parser) let(start scanner.Token, keyword scanner.Token) (Expression, error) {
+	_, err := p.consume(scanner.LEFT_PAREN, "Expect '(' before bindings.")
+	if err != nil {
+		return nil, err
+	}
+
+	var bindings []Binding
+	for !p.match(scanner.RIGHT_PAREN) && !p.isAtEnd() {
+		_, err := p.consume(scanner.LEFT_PAREN, "Expect '(' before binding.")
+		if err != nil {
+			return nil, err
+		}
+		name, err := p.consume(scanner.SYMBOL, "Expect variable name in binding.")
+		if err != nil {
+			return nil, err
+		}
+		value, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		_, err = p.consume(scanner.RIGHT_PAREN, "Expect ')' after binding.")
+		if err != nil {
+			return nil, err
+		}
+		bindings = append(bindings, Binding{Name: name, Value: value})
+	}
+
+	body, err := p.body()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.consume(scanner.RIGHT_PAREN, "Expect ')' after let body.")
+	if err != nil {
+		return nil, err
+	}
+
+	return Let{Keyword: keyword, Bindings: bindings, Body: body, Span: p.spanFrom(start)}, nil
+}
+
+// try parses (try body... (catch (name) handler...) (finally cleanup...)), where either one of the
+// clauses may be left out
+func (p *Parser) try(start scanner.Token, keyword scanner.Token) (Expression, error) {
+	var body []Expression
+	for !p.check(scanner.RIGHT_PAREN) && !p.isAtEnd() && !p.checkClause(scanner.CATCH) && !p.checkClause(scanner.FINALLY) {
+		expr, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		body = append(body, expr)
+	}
+
+	var catch *CatchClause
+	if p.checkClause(scanner.CATCH) {
+		p.advance()
+		p.advance()
+		_, err := p.consume(scanner.LEFT_PAREN, "Expect '(' before catch variable.")
+		if err != nil {
+			return nil, err
+		}
+		name, err := p.consume(scanner.SYMBOL, "Expect catch variable name.")
+		if err != nil {
+			return nil, err
+		}
+		_, err = p.consume(scanner.RIGHT_PAREN, "Expect ')' after catch variable.")
+		if err != nil {
+			return nil, err
+		}
+		handler, err := p.sequence("Expect ')' after catch clause.")
+		if err != nil {
+			return nil, err
+		}
+		catch = &CatchClause{Name: name, Body: handler}
+	}
+
+	var finally []Expression
+	if p.checkClause(scanner.FINALLY) {
+		p.advance()
+		p.advance()
+		cleanup, err := p.sequence("Expect ')' after finally clause.")
+		if err != nil {
+			return nil, err
+		}
+		finally = cleanup
+	}
+
+	if catch == nil && finally == nil {
+		p.error(p.peek(), "Expect catch or finally clause.")
+		return nil, errors.New("expect catch or finally clause")
+	}
+	_, err := p.consume(scanner.RIGHT_PAREN, "Expect ')' after try.")
+	if err != nil {
+		return nil, err
+	}
+
+	return Try{Keyword: keyword, Body: body, Catch: catch, Finally: finally, Span: p.spanFrom(start)}, nil
+}
+
+// handlerBind parses (handler-bind ((kind handler)...) body...)
+func (p *Parser) handlerBind(start scanner.Token, keyword scanner.Token) (Expression, error) {
+	_, err := p.consume(scanner.LEFT_PAREN, "Expect '(' before handlers.")
+	if err != nil {
+		return nil, err
+	}
+
+	var handlers []HandlerClause
+	for !p.match(scanner.RIGHT_PAREN) && !p.isAtEnd() {
+		_, err := p.consume(scanner.LEFT_PAREN, "Expect '(' before handler.")
+		if err != nil {
+			return nil, err
+		}
+		kind, err := p.consume(scanner.SYMBOL, "Expect condition kind in handler.")
+		if err != nil {
+			return nil, err
+		}
+		handler, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		_, err = p.consume(scanner.RIGHT_PAREN, "Expect ')' after handler.")
+		if err != nil {
+			return nil, err
+		}
+		handlers = append(handlers, HandlerClause{Kind: kind, Handler: handler})
+	}
+
+	body, err := p.body()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.consume(scanner.RIGHT_PAREN, "Expect ')' after handler-bind body.")
+	if err != nil {
+		return nil, err
+	}
+
+	return HandlerBind{Keyword: keyword, Handlers: handlers, Body: body, Span: p.spanFrom(start)}, nil
+}
+
+// restartCase parses (restart-case expr (name (params) body...)...)
+func (p *Parser) restartCase(start scanner.Token, keyword scanner.Token) (Expression, error) {
+	expr, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
 
+	var restarts []RestartClause
+	for !p.check(scanner.RIGHT_PAREN) && !p.isAtEnd() {
+		_, err := p.consume(scanner.LEFT_PAREN, "Expect '(' before restart.")
+		if err != nil {
+			return nil, err
+		}
+		name, err := p.consume(scanner.SYMBOL, "Expect restart name.")
+		if err != nil {
+			return nil, err
+		}
+		_, err = p.consume(scanner.LEFT_PAREN, "Expect '(' after restart name.")
+		if err != nil {
+			return nil, err
+		}
+		params, err := p.params()
+		if err != nil {
+			return nil, err
+		}
+		body, err := p.sequence("Expect ')' after restart body.")
+		if err != nil {
+			return nil, err
+		}
+		restarts = append(restarts, RestartClause{Name: name, Params: params, Body: body})
+	}
+	_, err = p.consume(scanner.RIGHT_PAREN, "Expect ')' after restart-case.")
+	if err != nil {
+		return nil, err
+	}
+
+	return RestartCase{Keyword: keyword, Expr: expr, Restarts: restarts, Span: p.spanFrom(start)}, nil
+}
+
+// sequence parses any number of expressions along with the closing parenthesis after them
+func (p *Parser) sequence(message string) ([]Expression, error) {
+	var exprs []Expression
+	for !p.check(scanner.RIGHT_PAREN) && !p.isAtEnd() {
+		expr, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+
+	_, err := p.consume(scanner.RIGHT_PAREN, message)
+	if err != nil {
+		return nil, err
+	}
+	return exprs, nil
+}
+
+func (p *Parser) paramList() ([]scanner.Token, error) {
 	_, err := p.consume(scanner.LEFT_PAREN, "Expect '(' after function name.")
 	if err != nil {
 		return nil, err
 	}
+	return p.params()
+}
+
+// params parses parameter names up to and including the closing parenthesis of a parameter list
+func (p *Parser) params() ([]scanner.Token, error) {
+	var params []scanner.Token
 
 	// Build a list of parameter names that will be assigned to the passed parameters at call-time
 	for !p.match(scanner.RIGHT_PAREN) && !p.isAtEnd() {
@@ -132,7 +676,163 @@ func (p *Parser) paramList() ([]scanner.Token, error) {
 
 }
 
+// quotation parses the datum following a quote or quasiquote. Quoted expressions are
+// kept as plain data (lists, atoms and symbols) and never turned into calls or definitions.
+// start is the opening parenthesis of a long form such as (quote x), which must then be closed
+func (p *Parser) quotation(start scanner.Token, keyword scanner.Token) (Expression, error) {
+	depth := 1
+	if keyword.Type == scanner.APOSTROPHE || keyword.Type == scanner.QUOTE {
+		depth = 0
+	}
+
+	datum, err := p.datum(depth)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.closeLongForm(start); err != nil {
+		return nil, err
+	}
+
+	if depth == 0 {
+		return Quote{Keyword: keyword, Datum: datum, Span: p.spanFrom(start)}, nil
+	}
+	return Quasiquote{Keyword: keyword, Datum: datum, Span: p.spanFrom(start)}, nil
+}
+
+// datum parses a single expression as data. depth counts the quasiquotes the datum is nested in,
+// so that unquotes are only parsed as code when they belong to the outermost quasiquote
+func (p *Parser) datum(depth int) (Expression, error) {
+	// Reader shorthands are expanded into their long forms, e.g. 'x becomes (quote x)
+	if p.match(scanner.APOSTROPHE, scanner.BACKQUOTE, scanner.COMMA, scanner.COMMA_AT) {
+		return p.prefixedDatum(p.previous(), p.previous(), depth)
+	}
+
+	if p.match(scanner.LEFT_PAREN) {
+		start := p.previous()
+
+		// Long forms of the shorthands are handled the same way
+		if p.match(scanner.QUOTE, scanner.QUASIQUOTE, scanner.UNQUOTE, scanner.UNQUOTE_SPLICING) {
+			return p.prefixedDatum(start, p.previous(), depth)
+		}
+
+		// () is the empty list, which is nil
+		if p.match(scanner.RIGHT_PAREN) {
+			return Atom{Value: nil, Span: p.spanFrom(start)}, nil
+		}
+
+		head, err := p.datum(depth)
+		if err != nil {
+			return nil, err
+		}
+
+		var tail []Expression
+		var dotted Expression
+		for !p.check(scanner.RIGHT_PAREN) && !p.isAtEnd() {
+			if p.match(scanner.DOT) {
+				dotted, err = p.datum(depth)
+				if err != nil {
+					return nil, err
+				}
+				break
+			}
+			expr, err := p.datum(depth)
+			if err != nil {
+				return nil, err
+			}
+			tail = append(tail, expr)
+		}
+
+		_, err = p.consume(scanner.RIGHT_PAREN, "Expect ')' after quoted list.")
+		if err != nil {
+			return nil, err
+		}
+		return ListExpr{Head: head, Tail: tail, Dotted: dotted, Span: p.spanFrom(start)}, nil
+	}
+
+	// Symbols, keywords and operators all become symbols when quoted
+	if p.match(scanner.SYMBOL) {
+		return Symbol{Name: p.previous()}, nil
+	}
+	if p.check(scanner.TRUE) || p.check(scanner.NIL) {
+		return p.atom()
+	}
+	if p.isKeyword() || p.match(scanner.QUOTE, scanner.QUASIQUOTE, scanner.UNQUOTE, scanner.UNQUOTE_SPLICING) {
+		return Symbol{Name: p.previous()}, nil
+	}
+	if p.isOperator() {
+		return Symbol{Name: p.previous()}, nil
+	}
+
+	// Leaves only numbers and strings
+	return p.atom()
+}
+
+// prefixedDatum parses the datum following a quote, quasiquote, unquote or unquote-splicing.
+// As with quotation, a start that is an opening parenthesis means the form must be closed
+func (p *Parser) prefixedDatum(start scanner.Token, prefix scanner.Token, depth int) (Expression, error) {
+	var name scanner.TokenType
+	switch prefix.Type {
+	case scanner.APOSTROPHE, scanner.QUOTE:
+		name = scanner.QUOTE
+	case scanner.BACKQUOTE, scanner.QUASIQUOTE:
+		name = scanner.QUASIQUOTE
+		depth++
+	case scanner.COMMA, scanner.UNQUOTE:
+		name = scanner.UNQUOTE
+	default:
+		name = scanner.UNQUOTE_SPLICING
+	}
+
+	// An unquote belonging to the outermost quasiquote holds code to be evaluated,
+	// while one inside a plain quote is simply data
+	if (name == scanner.UNQUOTE || name == scanner.UNQUOTE_SPLICING) && depth > 0 && !p.literal {
+		depth--
+		if depth == 0 {
+			expr, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.closeLongForm(start); err != nil {
+				return nil, err
+			}
+			return Unquote{Keyword: prefix, Expr: expr, Splicing: name == scanner.UNQUOTE_SPLICING, Span: p.spanFrom(start)}, nil
+		}
+	}
+
+	datum, err := p.datum(depth)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.closeLongForm(start); err != nil {
+		return nil, err
+	}
+
+	// The shorthand is replaced by a symbol naming its long form, positioned where the shorthand was
+	keyword := prefix
+	keyword.Type = name
+	keyword.Lexeme = scanner.KeywordsReverse[name]
+	return ListExpr{Head: Symbol{Name: keyword}, Tail: []Expression{datum}, Span: p.spanFrom(start)}, nil
+}
+
+// closeLongForm consumes the closing parenthesis of a long form like (quote x), where start is its opening one
+func (p *Parser) closeLongForm(start scanner.Token) error {
+	if start.Type != scanner.LEFT_PAREN {
+		return nil
+	}
+	_, err := p.consume(scanner.RIGHT_PAREN, "Expect ')' after quoted expression.")
+	return err
+}
+
 func (p *Parser) atom() (Expression, error) {
+	// Quoted expressions are data rather than code
+	if p.match(scanner.APOSTROPHE, scanner.BACKQUOTE) {
+		return p.quotation(p.previous(), p.previous())
+	}
+	if p.match(scanner.COMMA, scanner.COMMA_AT, scanner.UNQUOTE, scanner.UNQUOTE_SPLICING) {
+		p.error(p.previous(), "Unquote outside of quasiquote.")
+		return nil, errors.New("unquote outside of quasiquote")
+	}
+
 	// handles keywords as the first element of a list
 	// Native functions are probably a better way to do this
 	if p.isKeyword() {
@@ -140,8 +840,8 @@ func (p *Parser) atom() (Expression, error) {
 		return k, nil
 	}
 
-	// Works similarly to keywords. Native functions might've been better
-	if p.match(scanner.PLUS, scanner.MINUS, scanner.STAR, scanner.SLASH, scanner.EQUAL, scanner.LESS, scanner.GREATER) {
+	// Operators head their own lists, but evaluate to native functions anywhere else
+	if p.isOperator() {
 		// Handle operators
 		return Operator{Operator: p.previous()}, nil
 	}
@@ -152,13 +852,13 @@ func (p *Parser) atom() (Expression, error) {
 		var err error
 		switch prevValue.(type) {
 		case string:
-			return Atom{Value: prevValue, Type: scanner.STRING}, err
-		case float64:
-			return Atom{Value: prevValue, Type: scanner.NUMBER}, err
+			return Atom{Value: prevValue, Type: scanner.STRING, Span: p.previous().Span()}, err
+		case int64, *big.Int, *big.Rat, float64:
+			return Atom{Value: prevValue, Type: scanner.NUMBER, Span: p.previous().Span()}, err
 		default:
 			// Handle other types or error
 			message := "unexpected literal type: " + fmt.Sprintf("%T", prevValue)
-			ParseError(p.peek(), message)
+			p.error(p.peek(), message)
 			err = errors.New(message)
 		}
 		return Atom{Value: nil, Type: scanner.NIL}, err
@@ -171,14 +871,15 @@ func (p *Parser) atom() (Expression, error) {
 
 	if p.match(scanner.TRUE) {
 		// Handle true boolean literal
-		return Atom{Value: true}, nil
+		return Atom{Value: true, Span: p.previous().Span()}, nil
 	}
 
 	if p.match(scanner.NIL) {
 		// Handle nil
-		return Atom{Value: nil}, nil
+		return Atom{Value: nil, Span: p.previous().Span()}, nil
 	}
 
 	// If none of the above get triggered, something went wrong
+	p.error(p.peek(), "Unexpected token.")
 	return Atom{Value: nil}, errors.New("unexpected token: " + p.peek().Lexeme)
 }
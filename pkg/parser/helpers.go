@@ -54,12 +54,40 @@ func (p *Parser) consume(t scanner.TokenType, message string) (scanner.Token, er
 	if p.check(t) {
 		return p.advance(), nil
 	}
-	ParseError(p.peek(), message)
+	p.error(p.peek(), message)
 	return scanner.NewToken(scanner.OTHER, "", nil, 0), errors.New(message)
 }
 
+// spanFrom returns the span running from start to the end of the token that was just passed
+func (p *Parser) spanFrom(start scanner.Token) scanner.Span {
+	return start.Span().To(p.previous().Span())
+}
+
+// checkClause checks whether the parser is at a clause starting with the keyword t, like (catch (e) ...)
+func (p *Parser) checkClause(t scanner.TokenType) bool {
+	return p.check(scanner.LEFT_PAREN) && p.Tokens[p.Curr+1].Type == t
+}
+
+// isOperator matches any of the operator tokens
+func (p *Parser) isOperator() bool {
+	return p.match(scanner.PLUS, scanner.MINUS, scanner.STAR, scanner.SLASH, scanner.EQUAL, scanner.BANG_EQUAL,
+		scanner.LESS, scanner.LESS_EQUAL, scanner.GREATER, scanner.GREATER_EQUAL)
+}
+
 func (p *Parser) isKeyword() bool {
-	return p.match(scanner.DEFINE, scanner.SET, scanner.CONS, scanner.COND, scanner.CAR, scanner.CDR, scanner.NIL, scanner.TRUE, scanner.FALSE, scanner.ANDQ, scanner.ORQ, scanner.NOTQ, scanner.NUMBERQ, scanner.SYMBOLQ, scanner.LISTQ, scanner.NILQ)
+	return p.match(scanner.DEFINE, scanner.DEFVAR, scanner.DEFPARAMETER, scanner.LAMBDA, scanner.LET, scanner.LETSTAR, scanner.LETREC, scanner.BEGIN, scanner.SET, scanner.SETBANG, scanner.COND, scanner.TRY, scanner.CATCH, scanner.FINALLY, scanner.HANDLER_BIND, scanner.RESTART_CASE, scanner.DEFMACRO, scanner.NIL, scanner.TRUE, scanner.FALSE, scanner.SYMBOLQ)
+}
+
+// stringifyBody returns the string representation of a body, with its expressions separated by spaces
+func stringifyBody(body []Expression) string {
+	output := ""
+	for j, expr := range body {
+		if j > 0 {
+			output += " "
+		}
+		output += expr.String()
+	}
+	return output
 }
 
 // stringify returns string representation of passed object
@@ -5,8 +5,25 @@ import (
 )
 
 type Parser struct {
-	Tokens []scanner.Token
-	Curr   int
+	Tokens      []scanner.Token
+	Curr        int
+	Diagnostics scanner.Diagnostics
+	Macros      MacroExpander // expands macro calls while parsing, if set
+	literal     bool          // set while reading the forms of a macro call, where nothing is code
+	top         int           // index of the first token of the top-level form, the only place for a defmacro
+	expansions  int           // how many macro expansions the tokens being parsed are nested in
+}
+
+// maxExpansionDepth is how deeply macro expansions can be nested, so that a macro which always expands
+// into another call to itself is reported rather than recursing until the Go stack overflows
+const maxExpansionDepth = 10000
+
+// MacroExpander expands macros for the parser, so that macro calls are replaced by their expansions
+// before they are evaluated. Only macros defined by forms that have already been evaluated are known,
+// which is why the interpreter parses and evaluates top-level forms one at a time with Next
+type MacroExpander interface {
+	IsMacro(name string) bool
+	ExpandMacro(call ListExpr) ([]scanner.Token, error) // returns the tokens of the form the call expands into
 }
 
 func NewParser(tokens []scanner.Token) Parser {
@@ -16,16 +33,73 @@ func NewParser(tokens []scanner.Token) Parser {
 	}
 }
 
+// Parse parses every expression in the token stream. A form with a syntax error is skipped so that
+// the rest can still be parsed, and on failure the returned error holds the parser's Diagnostics for
+// every error found, alongside the expressions that did parse
 func (p *Parser) Parse() ([]Expression, error) {
 	var expressions []Expression
 
-	for !p.isAtEnd() {
-		expr, err := p.expr()
-		if err != nil {
-			return []Expression{}, err
+	for p.HasNext() {
+		if expr, ok := p.Next(); ok {
+			expressions = append(expressions, expr)
 		}
-		expressions = append(expressions, expr)
 	}
 
+	if len(p.Diagnostics) > 0 {
+		return expressions, p.Diagnostics
+	}
 	return expressions, nil
 }
+
+// Check parses every form in the token stream without expanding any macros, and returns the syntax errors
+// found. It lets the whole stream be checked before any of it is evaluated, even though the forms have
+// to be parsed one at a time to expand macros. A call to a macro known to Macros, or to one defined by a
+// defmacro in the stream, is read as data, since what it expands into is not known until it is evaluated
+func (p *Parser) Check() scanner.Diagnostics {
+	macros := syntaxOnly{known: p.Macros, defined: map[string]bool{}}
+	for j := 0; j+2 < len(p.Tokens); j++ {
+		if p.Tokens[j].Type == scanner.LEFT_PAREN && p.Tokens[j+1].Type == scanner.DEFMACRO {
+			macros.defined[p.Tokens[j+2].Lexeme] = true
+		}
+	}
+
+	checker := Parser{Tokens: p.Tokens, Macros: macros}
+	checker.Parse()
+	return checker.Diagnostics
+}
+
+// syntaxOnly stands in for the MacroExpander while checking syntax, expanding every macro call into nil
+type syntaxOnly struct {
+	known   MacroExpander
+	defined map[string]bool
+}
+
+func (s syntaxOnly) IsMacro(name string) bool {
+	return s.defined[name] || (s.known != nil && s.known.IsMacro(name))
+}
+
+func (s syntaxOnly) ExpandMacro(call ListExpr) ([]scanner.Token, error) {
+	at := func(tokenType scanner.TokenType, lexeme string) scanner.Token {
+		span := call.Position()
+		return scanner.Token{Type: tokenType, Lexeme: lexeme, Line: span.Line, Column: span.Column, File: span.File, Start: span.Start, End: span.End}
+	}
+	return []scanner.Token{at(scanner.LEFT_PAREN, "("), at(scanner.RIGHT_PAREN, ")"), at(scanner.EOF, "EOF")}, nil
+}
+
+// HasNext reports whether there are forms left to parse
+func (p *Parser) HasNext() bool {
+	return !p.isAtEnd()
+}
+
+// Next parses the next top-level form. A form with a syntax error is skipped, with the error recorded in
+// Diagnostics, and reported as not ok
+func (p *Parser) Next() (Expression, bool) {
+	start := p.Curr
+	p.top = start
+	expr, err := p.expr()
+	if err != nil {
+		p.synchronize(start)
+		return nil, false
+	}
+	return expr, true
+}
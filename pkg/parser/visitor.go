@@ -7,5 +7,15 @@ type ExprVisitor interface {
 	VisitAtomExpr(l Atom) (interface{}, error)
 	VisitSymbolExpr(s Symbol) (interface{}, error)
 	VisitFuncDefinitionExpr(f FuncDefinition) (interface{}, error)
+	VisitVarDefinitionExpr(d VarDefinition) (interface{}, error)
+	VisitMacroDefinitionExpr(m MacroDefinition) (interface{}, error)
 	VisitCallExpr(c Call) (interface{}, error)
-}
\ No newline at end of file
+	VisitLambdaExpr(l Lambda) (interface{}, error)
+	VisitLetExpr(l Let) (interface{}, error)
+	VisitTryExpr(t Try) (interface{}, error)
+	VisitHandlerBindExpr(h HandlerBind) (interface{}, error)
+	VisitRestartCaseExpr(r RestartCase) (interface{}, error)
+	VisitQuoteExpr(q Quote) (interface{}, error)
+	VisitQuasiquoteExpr(q Quasiquote) (interface{}, error)
+	VisitUnquoteExpr(u Unquote) (interface{}, error)
+}
@@ -2,26 +2,36 @@ package scanner
 
 import (
 	"fmt"
-	// "log"
-	"os"
-	//"unicode/utf8"
+	"strings"
 )
 
-var hadErrorFlag bool = false
+// Phases that a Diagnostic can come from
+const (
+	ScanPhase  = "Scan"
+	ParsePhase = "Parse"
+)
 
-func HadError() bool {
-	return hadErrorFlag
+// Diagnostic is a single error found in source code, along with where it was found and which
+// phase found it. Diagnostics are collected on each Scanner and Parser rather than printed,
+// so separate interpreters never share error state
+type Diagnostic struct {
+	Phase   string
+	Span    Span
+	Where   string
+	Message string
 }
 
-func SetErrorFlag(val bool) {
-	hadErrorFlag = val
+func (d Diagnostic) Error() string {
+	return fmt.Sprintf("[%s] %s Error%s: %s", d.Span, d.Phase, d.Where, d.Message)
 }
 
-func LoxError(line int, message string) {
-	Report(line, "", message)
-}
+// Diagnostics lets every diagnostic from a scan or parse be returned as a single error
+type Diagnostics []Diagnostic
 
-func Report(line int, where string, message string) {
-	fmt.Fprintf(os.Stderr, "[line %d] Parse Error%s: %s\n", line, where, message)
-	hadErrorFlag = true
-}
\ No newline at end of file
+func (d Diagnostics) Error() string {
+	messages := make([]string, len(d))
+	for j, diagnostic := range d {
+		messages[j] = diagnostic.Error()
+	}
+	return strings.Join(messages, "\n")
+}
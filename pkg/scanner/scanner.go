@@ -4,53 +4,81 @@ import (
 	"fmt"
 	// "log"
 	// "os"
+	"math/big"
 	"strconv"
+	"strings"
 	"unicode"
 	"unicode/utf8"
 )
 
 var Keywords = map[string]TokenType{
-	"define":  DEFINE,
-	"set":     SET,
-	"cons":    CONS,
-	"cond":    COND,
-	"car":     CAR,
-	"cdr":     CDR,
-	"nil":     NIL,
-	"true":    TRUE,
-	"and?":    ANDQ,
-	"or?":     ORQ,
-	"not?":    NOTQ,
-	"number?": NUMBERQ,
-	"symbol?": SYMBOLQ,
-	"list?":   LISTQ,
-	"nil?":    NILQ,
+	"define":       DEFINE,
+	"defvar":       DEFVAR,
+	"defparameter": DEFPARAMETER,
+	"lambda":       LAMBDA,
+	"let":          LET,
+	"let*":         LETSTAR,
+	"letrec":       LETREC,
+	"begin":        BEGIN,
+	"set":          SET,
+	"set!":         SETBANG,
+	"cond":         COND,
+	"try":          TRY,
+	"catch":        CATCH,
+	"finally":      FINALLY,
+	"handler-bind": HANDLER_BIND,
+	"restart-case": RESTART_CASE,
+	"defmacro":     DEFMACRO,
+	"nil":          NIL,
+	"true":         TRUE,
+	"symbol?":      SYMBOLQ,
+
+	"quote":            QUOTE,
+	"quasiquote":       QUASIQUOTE,
+	"unquote":          UNQUOTE,
+	"unquote-splicing": UNQUOTE_SPLICING,
 }
 
 var KeywordsReverse = map[TokenType]string{
-	DEFINE:  "define",
-	SET:     "set",
-	CONS:    "cons",
-	COND:    "cond",
-	CAR:     "car",
-	CDR:     "cdr",
-	NIL:     "nil",
-	TRUE:    "true",
-	ANDQ:    "and?",
-	ORQ:     "or?",
-	NOTQ:    "not?",
-	NUMBERQ: "number?",
-	SYMBOLQ: "symbol?",
-	LISTQ:   "list?",
-	NILQ:    "nil?",
+	DEFINE:       "define",
+	DEFVAR:       "defvar",
+	DEFPARAMETER: "defparameter",
+	LAMBDA:       "lambda",
+	LET:          "let",
+	LETSTAR:      "let*",
+	LETREC:       "letrec",
+	BEGIN:        "begin",
+	SET:          "set",
+	SETBANG:      "set!",
+	COND:         "cond",
+	TRY:          "try",
+	CATCH:        "catch",
+	FINALLY:      "finally",
+	HANDLER_BIND: "handler-bind",
+	RESTART_CASE: "restart-case",
+	DEFMACRO:     "defmacro",
+	NIL:          "nil",
+	TRUE:         "true",
+	SYMBOLQ:      "symbol?",
+
+	QUOTE:            "quote",
+	QUASIQUOTE:       "quasiquote",
+	UNQUOTE:          "unquote",
+	UNQUOTE_SPLICING: "unquote-splicing",
 }
 
 type Scanner struct {
-	Source string
-	Tokens []Token
-	Start  int
-	Curr   int
-	Line   int
+	Source        string
+	File          string // name of the file being scanned, attached to every token
+	CaseSensitive bool   // symbols and keywords are lowercased unless this is set
+	Tokens        []Token
+	Diagnostics   Diagnostics
+	Start         int
+	Curr          int
+	Line          int
+	lineStart     int // offset of the first character of the current line, for columns
+	startLine     int // line and column the current token starts at
+	startColumn   int
 }
 
 func NewScanner(sourceText string) Scanner {
@@ -64,22 +92,38 @@ func NewScanner(sourceText string) Scanner {
 	}
 }
 
+// isAtEnd checks whether the scanner has consumed the whole source. Start and Curr are byte offsets
 func (s *Scanner) isAtEnd() bool {
-	length := utf8.RuneCountInString(s.Source)
-	return s.Curr >= length
+	return s.Curr >= len(s.Source)
 }
 
+// advance consumes and returns the next rune, which may span several bytes
 func (s *Scanner) advance() rune {
-	ch := rune(s.Source[s.Curr])
-	s.Curr++
+	ch, size := utf8.DecodeRuneInString(s.Source[s.Curr:])
+	s.Curr += size
 	return ch
 }
 
+// peek returns the next rune without consuming it
 func (s *Scanner) peek() rune {
 	if s.isAtEnd() {
 		return '\000'
 	}
-	return rune(s.Source[s.Curr])
+	ch, _ := utf8.DecodeRuneInString(s.Source[s.Curr:])
+	return ch
+}
+
+// peekNext returns the rune after the next one without consuming either
+func (s *Scanner) peekNext() rune {
+	if s.isAtEnd() {
+		return '\000'
+	}
+	_, size := utf8.DecodeRuneInString(s.Source[s.Curr:])
+	if s.Curr+size >= len(s.Source) {
+		return '\000'
+	}
+	ch, _ := utf8.DecodeRuneInString(s.Source[s.Curr+size:])
+	return ch
 }
 
 func (s *Scanner) addToken(thisType TokenType) {
@@ -89,21 +133,99 @@ func (s *Scanner) addToken(thisType TokenType) {
 
 func (s *Scanner) addTokenWithTypeAndLiteral(thisType TokenType, literal interface{}) {
 	text := s.Source[s.Start:s.Curr]
-	s.Tokens = append(s.Tokens, Token{Type: thisType, Lexeme: text, Literal: literal, Line: s.Line})
+	s.addTokenWithLexeme(thisType, text, literal)
+}
+
+// addTokenWithLexeme adds a token whose lexeme differs from the source text, such as a lowercased symbol
+func (s *Scanner) addTokenWithLexeme(thisType TokenType, lexeme string, literal interface{}) {
+	s.Tokens = append(s.Tokens, Token{
+		Type:    thisType,
+		Lexeme:  lexeme,
+		Literal: literal,
+		Line:    s.startLine,
+		Column:  s.startColumn,
+		File:    s.File,
+		Start:   s.Start,
+		End:     s.Curr,
+	})
 }
 
 func (s *Scanner) ScanTokens() []Token {
 	// Driving loop
 	for !s.isAtEnd() {
 		s.Start = s.Curr
+		s.startLine, s.startColumn = s.Line, utf8.RuneCountInString(s.Source[s.lineStart:s.Curr])+1
 		s.ScanToken()
 	}
 
 	// Add EOF token
-	s.Tokens = append(s.Tokens, Token{EOF, "EOF", nil, s.Line})
+	s.Start = s.Curr
+	s.startLine, s.startColumn = s.Line, utf8.RuneCountInString(s.Source[s.lineStart:s.Curr])+1
+	s.addTokenWithLexeme(EOF, "EOF", nil)
+
+	s.Tokens = s.removeDatumComments(s.Tokens)
 	return s.Tokens
 }
 
+// removeDatumComments drops every #; token along with the tokens of the expression it comments out
+func (s *Scanner) removeDatumComments(tokens []Token) []Token {
+	kept := tokens[:0:0]
+	for j := 0; j < len(tokens); {
+		if tokens[j].Type != DATUM_COMMENT {
+			kept = append(kept, tokens[j])
+			j++
+			continue
+		}
+		end, ok := skipDatum(tokens, j+1)
+		if !ok {
+			s.Diagnostics = append(s.Diagnostics, Diagnostic{
+				Phase:   ScanPhase,
+				Span:    tokens[j].Span(),
+				Message: "Expect expression after '#;'",
+			})
+			end = j + 1
+		}
+		j = end
+	}
+	return kept
+}
+
+// skipDatum returns the index just past the expression starting at index j, or false if there is no
+// expression there. Quote prefixes belong to the expression they quote, and an expression that is
+// itself commented out is skipped along with the one after it, so #; #; a b comments out both
+func skipDatum(tokens []Token, j int) (int, bool) {
+	for j < len(tokens) {
+		switch tokens[j].Type {
+		case APOSTROPHE, BACKQUOTE, COMMA, COMMA_AT:
+			j++
+		case DATUM_COMMENT:
+			end, ok := skipDatum(tokens, j+1)
+			if !ok {
+				return j, false
+			}
+			j = end
+		case LEFT_PAREN:
+			depth := 0
+			for ; j < len(tokens) && tokens[j].Type != EOF; j++ {
+				if tokens[j].Type == LEFT_PAREN {
+					depth++
+				} else if tokens[j].Type == RIGHT_PAREN {
+					depth--
+				}
+				if depth == 0 {
+					return j + 1, true
+				}
+			}
+			return j, false
+		case RIGHT_PAREN, EOF:
+			return j, false
+		default:
+			return j + 1, true
+		}
+	}
+	return j, false
+}
+
 func (s *Scanner) ScanToken() {
 	ch := s.advance()
 	switch ch {
@@ -113,24 +235,78 @@ func (s *Scanner) ScanToken() {
 		s.addToken(RIGHT_PAREN)
 	case '.':
 		s.addToken(DOT)
+	// A sign directly followed by a digit starts a number like -5 rather than being an operator
 	case '-':
-		s.addToken(MINUS)
+		if isDigit(s.peek()) {
+			s.tokenizeNumber()
+		} else {
+			s.addToken(MINUS)
+		}
 	case '+':
-		s.addToken(PLUS)
+		if isDigit(s.peek()) {
+			s.tokenizeNumber()
+		} else {
+			s.addToken(PLUS)
+		}
 	case '*':
 		s.addToken(STAR)
 	case '=':
 		s.addToken(EQUAL)
 	case '<':
-		s.addToken(LESS)
+		if s.match('=') {
+			s.addToken(LESS_EQUAL)
+		} else {
+			s.addToken(LESS)
+		}
 	case '>':
-		s.addToken(GREATER)
+		if s.match('=') {
+			s.addToken(GREATER_EQUAL)
+		} else {
+			s.addToken(GREATER)
+		}
+	case ';': // A semicolon comments out the rest of the line
+		for !s.isAtEnd() && s.peek() != '\n' {
+			s.advance()
+		}
+	case '#':
+		if s.match('|') {
+			s.blockComment()
+		} else if s.match(';') {
+			s.addToken(DATUM_COMMENT)
+		} else if s.match('x') || s.match('X') {
+			s.tokenizeRadixNumber(16)
+		} else if s.match('b') || s.match('B') {
+			s.tokenizeRadixNumber(2)
+		} else if s.match('o') || s.match('O') {
+			s.tokenizeRadixNumber(8)
+		} else {
+			s.error("Unexpected character: #")
+		}
+	case '!':
+		if s.match('=') {
+			s.addToken(BANG_EQUAL)
+		} else {
+			s.error("Unexpected character: !")
+		}
+	// Reader shorthands for quote, quasiquote, unquote and unquote-splicing
+	case '\'':
+		s.addToken(APOSTROPHE)
+	case '`':
+		s.addToken(BACKQUOTE)
+	case ',':
+		if s.match('@') {
+			s.addToken(COMMA_AT)
+		} else {
+			s.addToken(COMMA)
+		}
 	case '/':
 		if s.match('/') {
 			for !s.isAtEnd() && s.peek() != '\n' {
 				s.advance()
 				//fmt.Println(s.peek())
 			}
+		} else if s.match('=') { // /= is another spelling of !=
+			s.addToken(BANG_EQUAL)
 		} else {
 			s.addToken(SLASH)
 		}
@@ -138,119 +314,298 @@ func (s *Scanner) ScanToken() {
 	case '\r':
 	case '\t':
 	case '\n':
-		s.Line++
+		s.newline()
 	// Handle strings
 	case '"':
 		s.tokenizeString()
 	default:
-		if unicode.IsDigit(rune(ch)) {
+		if isDigit(ch) {
 			s.tokenizeNumber()
-		} else if unicode.IsLetter(rune(ch)) || ch == '_' {
+		} else if unicode.IsLetter(ch) || ch == '_' || ch == '&' { // & begins parameter markers like &rest
 			s.tokenizeSymbol()
 		} else {
-			errorStr := fmt.Sprintf("Unexpected character: %c at line %d", ch, s.Line)
-			LoxError(s.Line, errorStr)
+			errorStr := fmt.Sprintf("Unexpected character: %c", ch)
+			s.error(errorStr)
 		}
 	}
 
 }
 
+// blockComment skips a comment between #| and |#. Block comments nest, so a block of code that
+// already contains one can be commented out as a whole
+func (s *Scanner) blockComment() {
+	depth := 1
+	for !s.isAtEnd() {
+		ch := s.advance()
+		switch {
+		case ch == '\n':
+			s.newline()
+		case ch == '#' && s.match('|'):
+			depth++
+		case ch == '|' && s.match('#'):
+			depth--
+			if depth == 0 {
+				return
+			}
+		}
+	}
+	s.error("Unterminated block comment")
+}
+
+// error records a diagnostic for the token currently being scanned
+func (s *Scanner) error(message string) {
+	s.Diagnostics = append(s.Diagnostics, Diagnostic{
+		Phase:   ScanPhase,
+		Span:    Span{File: s.File, Line: s.startLine, Column: s.startColumn, Start: s.Start, End: s.Curr},
+		Message: message,
+	})
+}
+
+// HadError reports whether any diagnostics were recorded while scanning
+func (s *Scanner) HadError() bool {
+	return len(s.Diagnostics) > 0
+}
+
+// newline moves the scanner onto the next line
+func (s *Scanner) newline() {
+	s.Line++
+	s.lineStart = s.Curr
+}
+
 func (s *Scanner) match(expected rune) bool {
 	if s.isAtEnd() {
 		return false
 	}
-	if rune(s.Source[s.Curr]) != expected {
+	if s.peek() != expected {
 		return false
 	}
-	s.Curr++
+	s.advance()
 	return true
 }
 
 func (s *Scanner) tokenizeString() {
-	// Track initial position
+	// The literal is built up separately from the lexeme since escape sequences get replaced
+	var literal strings.Builder
 	unterminated := true
 
-	//s.Curr++
-
 	// Iterate until end of string or end of file
-	for s.Curr < len(s.Source) {
+	for !s.isAtEnd() {
+		ch := s.advance()
 
 		// Break at closing quote
-		if s.Source[s.Curr] == '"' {
-			s.Curr++
+		if ch == '"' {
 			unterminated = false
 			break
 		}
 
 		// Handle newlines
-		if s.Source[s.Curr] == '\n' {
-			s.Line++
+		if ch == '\n' {
+			s.newline()
 		}
 
-		s.Curr++
+		if ch == '\\' {
+			s.escapeSequence(&literal)
+			continue
+		}
+
+		literal.WriteRune(ch)
 	}
 
 	// Check for unterminated string
 	if unterminated {
-		// Set current position to end of file to prevent further iteration
-		s.Curr = len(s.Source)
-		errorStr := fmt.Sprintf("Unterminated string at line %d", s.Line)
-		LoxError(s.Line, errorStr)
+		errorStr := "Unterminated string"
+		s.error(errorStr)
 	} else {
-		// Return token using substring created from initial and current positions
-		s.addTokenWithTypeAndLiteral(STRING, s.Source[s.Start+1:s.Curr-1])
+		s.addTokenWithTypeAndLiteral(STRING, literal.String())
+	}
+}
+
+// escapeSequence reads the rest of an escape sequence after its backslash, and writes the character it
+// stands for. Supports \n, \t, \r, \\, \" and \u{...} with a hexadecimal code point
+func (s *Scanner) escapeSequence(literal *strings.Builder) {
+	if s.isAtEnd() {
+		return // reported as an unterminated string
 	}
 
-	// Return token using substring created from initial and current positions
+	ch := s.advance()
+	switch ch {
+	case 'n':
+		literal.WriteRune('\n')
+	case 't':
+		literal.WriteRune('\t')
+	case 'r':
+		literal.WriteRune('\r')
+	case '\\', '"':
+		literal.WriteRune(ch)
+	case 'u':
+		if !s.match('{') {
+			s.error("Expect '{' after \\u in escape sequence")
+			return
+		}
+		start := s.Curr
+		for !s.isAtEnd() && s.peek() != '}' && s.peek() != '"' {
+			s.advance()
+		}
+		digits := s.Source[start:s.Curr]
+		if !s.match('}') {
+			s.error("Expect '}' after code point in escape sequence")
+			return
+		}
+		codePoint, err := strconv.ParseUint(digits, 16, 32)
+		if err != nil || !utf8.ValidRune(rune(codePoint)) {
+			s.error(fmt.Sprintf("Invalid code point in escape sequence: %s", digits))
+			return
+		}
+		literal.WriteRune(rune(codePoint))
+	default:
+		if ch == '\n' {
+			s.newline()
+		}
+		s.error(fmt.Sprintf("Invalid escape sequence: \\%c", ch))
+	}
 }
 
-// Number reader for Scanner
+// Number reader for Scanner, called after the first digit or sign of the number. Numbers with a decimal
+// point or an exponent, like 2.5 or 1e10, are floats, and all others are exact: integers, which are int64
+// unless they are too large and need a *big.Int, and fractions such as -1/3, which are *big.Rat unless
+// they reduce to an integer
 func (s *Scanner) tokenizeNumber() {
 	// Track initial position and whether a dot has been found
 	foundDot := false
 
 	// Iterate until end of number or end of file
 	// If s.Curr has not overflowed, and the current character is a digit or a dot
-	for s.Curr < len(s.Source) && (unicode.IsDigit(rune(s.Source[s.Curr])) || s.Source[s.Curr] == '.') {
+	for !s.isAtEnd() && (isDigit(s.peek()) || s.peek() == '.') {
 
 		// Check for dot
-		if s.Source[s.Curr] == '.' {
+		if s.peek() == '.' {
 			// Return error if dot has already been found
 			if foundDot {
-				errorStr := fmt.Sprintf("Invalid number at line %d", s.Line)
-				LoxError(s.Line, errorStr)
+				errorStr := "Invalid number"
+				s.error(errorStr)
 			}
 			// Otherwise, set foundDot to true and skip to next character
 			foundDot = true
 		}
 		// Iterate to next character
-		s.Curr++
+		s.advance()
+	}
+
+	// An exponent is only part of the number if digits follow it, otherwise the number ends before the e
+	foundExponent := false
+	if s.peek() == 'e' || s.peek() == 'E' {
+		mark := s.Curr
+		s.advance()
+		if s.peek() == '+' || s.peek() == '-' {
+			s.advance()
+		}
+		for !s.isAtEnd() && isDigit(s.peek()) {
+			foundExponent = true
+			s.advance()
+		}
+		if !foundExponent {
+			s.Curr = mark
+		}
+	}
+
+	if foundDot || foundExponent {
+		floatVal, err := strconv.ParseFloat(s.Source[s.Start:s.Curr], 64)
+		if err != nil {
+			errorStr := "Invalid number"
+			s.error(errorStr)
+		}
+		// Return token using substring created from initial and current positions
+		s.addTokenWithTypeAndLiteral(NUMBER, floatVal)
+		return
 	}
 
-	floatVal, err := strconv.ParseFloat(s.Source[s.Start:s.Curr], 64)
+	// A slash followed by a digit makes the number a fraction
+	if s.peek() == '/' && isDigit(s.peekNext()) {
+		s.advance()
+		for !s.isAtEnd() && isDigit(s.peek()) {
+			s.advance()
+		}
+	}
+
+	value, ok := exactNumber(s.Source[s.Start:s.Curr])
+	if !ok {
+		errorStr := "Invalid number"
+		s.error(errorStr)
+	}
+	s.addTokenWithTypeAndLiteral(NUMBER, value)
+}
+
+// tokenizeRadixNumber reads an integer written in another base after its prefix, such as #xFF or #b-101
+func (s *Scanner) tokenizeRadixNumber(base int) {
+	digitsStart := s.Curr
+	if s.peek() == '+' || s.peek() == '-' {
+		s.advance()
+	}
+	for !s.isAtEnd() && (unicode.IsLetter(s.peek()) || unicode.IsDigit(s.peek())) {
+		s.advance()
+	}
+
+	digits := s.Source[digitsStart:s.Curr]
+	if n, err := strconv.ParseInt(digits, base, 64); err == nil {
+		s.addTokenWithTypeAndLiteral(NUMBER, n)
+		return
+	}
+	n, ok := new(big.Int).SetString(digits, base)
+	if !ok {
+		errorStr := "Invalid number"
+		s.error(errorStr)
+		return
+	}
+	s.addTokenWithTypeAndLiteral(NUMBER, n)
+}
 
-	if err != nil {
-		errorStr := fmt.Sprintf("Invalid number at line %d", s.Line)
-		LoxError(s.Line, errorStr)
+// exactNumber parses an integer or fraction into the smallest type that holds it
+func exactNumber(text string) (interface{}, bool) {
+	if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return n, true
+	}
+	r, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return nil, false
+	}
+	if !r.IsInt() {
+		return r, true
 	}
-	// Return token using substring created from initial and current positions
-	s.addTokenWithTypeAndLiteral(NUMBER, floatVal)
+	if r.Num().IsInt64() {
+		return r.Num().Int64(), true
+	}
+	return new(big.Int).Set(r.Num()), true
 }
 
 // Identifier reader for Scanner
 // Note that although an error is never returned, it is good practice to provide support for it
 func (s *Scanner) tokenizeSymbol() {
 	// Iterate until end of identifier or end of file
-	for s.Curr < len(s.Source) && (unicode.IsLetter(rune(s.Source[s.Curr])) || unicode.IsDigit(rune(s.Source[s.Curr])) || s.Source[s.Curr] == '_' || s.Source[s.Curr] == '?') {
-		s.Curr++
+	for !s.isAtEnd() && isSymbolChar(s.peek()) {
+		s.advance()
 	}
 
-	// Check for existing keyword
+	// Symbols and keywords are case insensitive by default, unlike the contents of strings
 	symbol := s.Source[s.Start:s.Curr]
+	if !s.CaseSensitive {
+		symbol = strings.ToLower(symbol)
+	}
+
+	// Check for existing keyword
 	if tokentype, exists := Keywords[symbol]; exists {
-		s.addToken(tokentype)
+		s.addTokenWithLexeme(tokentype, symbol, nil)
 	} else {
 		// Set to default value if the key is not found
-		s.addToken(SYMBOL)
+		s.addTokenWithLexeme(SYMBOL, symbol, nil)
 	}
 }
+
+// isDigit only accepts ASCII digits, since those are the only ones numbers can be parsed from
+func isDigit(ch rune) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+// isSymbolChar reports whether ch can appear in a symbol after its first character
+func isSymbolChar(ch rune) bool {
+	return unicode.IsLetter(ch) || unicode.IsDigit(ch) || ch == '_' || ch == '?' || ch == '!' || ch == '-' || ch == '*' || ch == '/'
+}
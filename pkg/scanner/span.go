@@ -0,0 +1,62 @@
+package scanner
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Span is a region of source code. Line and Column locate its first character, while Start and End
+// are byte offsets into the source, with End being exclusive
+type Span struct {
+	File   string
+	Line   int
+	Column int
+	Start  int
+	End    int
+}
+
+// To returns a span running from the start of s to the end of other
+func (s Span) To(other Span) Span {
+	s.End = other.End
+	return s
+}
+
+// String returns the position of the span, such as "line 3:7" or "main.lsp:3:7"
+func (s Span) String() string {
+	if s.File != "" {
+		return fmt.Sprintf("%s:%d:%d", s.File, s.Line, s.Column)
+	}
+	if s.Column > 0 {
+		return fmt.Sprintf("line %d:%d", s.Line, s.Column)
+	}
+	return fmt.Sprintf("line %d", s.Line)
+}
+
+// Underline returns the line of source that the span starts on with a row of carets beneath the span.
+// Spans running over several lines are only underlined up to the end of their first line
+func Underline(source string, s Span) string {
+	if s.Start < 0 || s.Start > len(source) || s.End < s.Start {
+		return ""
+	}
+
+	lineStart := strings.LastIndexByte(source[:s.Start], '\n') + 1
+	lineEnd := strings.IndexByte(source[s.Start:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(source)
+	} else {
+		lineEnd += s.Start
+	}
+	end := s.End
+	if end > lineEnd {
+		end = lineEnd
+	}
+
+	line := strings.TrimRight(source[lineStart:lineEnd], "\r")
+	padding := strings.Repeat(" ", utf8.RuneCountInString(source[lineStart:s.Start]))
+	width := utf8.RuneCountInString(source[s.Start:end])
+	if width < 1 {
+		width = 1
+	}
+	return line + "\n" + padding + strings.Repeat("^", width)
+}
@@ -4,12 +4,16 @@ import (
 	"fmt"
 )
 
-// Token struct represents a token with its type, lexeme, literal value, and the line it appears on.
+// Token struct represents a token with its type, lexeme, literal value, and where it appears in the source.
 type Token struct {
 	Type    TokenType
 	Lexeme  string
 	Literal interface{}
 	Line    int
+	Column  int    // column of the first character, counted in characters from 1
+	File    string // name of the source file, if there is one
+	Start   int    // byte offsets of the token in the source, with End being exclusive
+	End     int
 }
 
 // NewToken is a constructor function for creating a new Token instance.
@@ -22,7 +26,12 @@ func NewToken(tokenType TokenType, lexeme string, literal interface{}, line int)
 	}
 }
 
+// Span returns the region of source code that the token covers
+func (t Token) Span() Span {
+	return Span{File: t.File, Line: t.Line, Column: t.Column, Start: t.Start, End: t.End}
+}
+
 // String method provides a string representation of the Token.
 func (t Token) String() string {
 	return fmt.Sprintf("%d %s %v", t.Type, t.Lexeme, t.Literal)
-}
\ No newline at end of file
+}
@@ -12,11 +12,19 @@ const (
 	SEMICOLON
 	SLASH
 	STAR
+	APOSTROPHE
+	BACKQUOTE
+	COMMA
 
 	// One or two character tokens.
 	EQUAL
+	BANG_EQUAL
 	GREATER
+	GREATER_EQUAL
 	LESS
+	LESS_EQUAL
+	COMMA_AT
+	DATUM_COMMENT
 
 	// Literals.
 	SYMBOL
@@ -25,21 +33,30 @@ const (
 
 	// Keywords.
 	DEFINE
+	DEFVAR
+	DEFPARAMETER
+	LAMBDA
+	LET
+	LETSTAR
+	LETREC
+	BEGIN
 	SET
-	CONS
+	SETBANG
 	COND
-	CAR
-	CDR
+	TRY
+	CATCH
+	FINALLY
+	HANDLER_BIND
+	RESTART_CASE
+	DEFMACRO
 	NIL
 	TRUE
 	FALSE
-	ANDQ
-	ORQ
-	NOTQ
-	NUMBERQ
 	SYMBOLQ
-	LISTQ
-	NILQ
+	QUOTE
+	QUASIQUOTE
+	UNQUOTE
+	UNQUOTE_SPLICING
 
 	WHITESPACE
 	OTHER
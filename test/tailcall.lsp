@@ -0,0 +1,27 @@
+// Runs a tail-recursive loop of a million iterations. Without tail calls every iteration
+// would hold onto its own call frame, so the loop would overflow the call stack.
+// Each check exits with status 1 if it fails, so that make stops
+
+(define check (actual expected)
+    (cond
+        (= expected actual)
+            "OK"
+        true
+            (exit 1)))
+
+(define countdown (n)
+    (cond
+        (= n 0)
+            "done"
+        true
+            (countdown (- n 1))))
+
+(check (countdown 1000000) "done")
+
+""
+"Tail calls through let and between mutually recursive functions"
+(define isEven (n) (cond (= n 0) true true (let ((next (- n 1))) (isOdd next))))
+(define isOdd (n) (cond (= n 0) nil true (isEven (- n 1))))
+
+(check (isEven 1000000) true)
+(check (isOdd 1000001) true)
@@ -58,6 +58,8 @@
 "Test type checking functions"
 (assertEquals (number? 5) true)
 (assertEquals (symbol? x) true)
+(assertEquals (symbol? 5) nil)
+(assertEquals (nil? (symbol? 5)) true)
 (assertEquals (list? myList) true)
 (assertEquals (nil? nil) true)
 
@@ -86,4 +88,365 @@
 ""
 "Testing global variable assignment and usage"
 (set globalVar 10)
-(assertEquals globalVar 10)
\ No newline at end of file
+(assertEquals globalVar 10)
+
+""
+"Test cons cells"
+(set pair (cons 1 2))
+(assertEquals (car pair) 1)
+(assertEquals (cdr pair) 2)
+(set consed (cons 1 (2 3)))
+(assertEquals (car consed) 1)
+(assertEquals (car (cdr consed)) 2)
+(assertEquals (cdr (cdr (cdr consed))) nil)
+(assertEquals (list? consed) true)
+(cons 1 (2 3))
+(cons 1 2)
+(1 2 . 3)
+
+""
+"Test quote and quasiquote"
+(set symbols '(a b c))
+(assertEquals (car symbols) 'a)
+(assertEquals (symbol? (car (cdr symbols))) true)
+(assertEquals (car (quote (1 2))) 1)
+(assertEquals (cdr '(1 . 2)) 2)
+(assertEquals (cdr '()) nil)
+(set n 2)
+(set spliced '(3 4))
+`(1 ,n ,@spliced 5)
+`(n ,(+ n 1) (nested ,n))
+'(define f (x) 'x)
+
+""
+"Test anonymous functions"
+(define applyTwice (f x) (f (f x)))
+(define makeAdder (n) (lambda (x) (+ x n)))
+(set addThree (makeAdder 3))
+(assertEquals (addThree 4) 7)
+(assertEquals ((makeAdder 10) 5) 15)
+(assertEquals ((lambda (a b) (* a b)) 6 7) 42)
+(assertEquals (applyTwice (lambda (x) (* x x)) 3) 81)
+(assertEquals (applyTwice addThree 1) 7)
+
+""
+"Test local bindings"
+(define hypotenuseSquared (a b) (let ((aa (* a a)) (bb (* b b))) (+ aa bb)))
+(assertEquals (hypotenuseSquared 3 4) 25)
+(set shadowed 1)
+(assertEquals (let ((shadowed 2) (other shadowed)) other) 1)
+(assertEquals (let* ((shadowed 2) (other shadowed)) other) 2)
+(assertEquals shadowed 1)
+(assertEquals (letrec ((isEven (lambda (n) (cond (= n 0) true true (isOdd (- n 1)))))
+                       (isOdd (lambda (n) (cond (= n 0) nil true (isEven (- n 1))))))
+                (isEven 10))
+              true)
+
+""
+"Test sequencing"
+(assertEquals (begin 1 2 3) 3)
+(define logThenSquare (x)
+    "squaring"
+    (* x x))
+(assertEquals (logThenSquare 5) 25)
+(assertEquals ((lambda (x) (set y x) (+ y 1)) 1) 2)
+(assertEquals (let ((a 1)) (set b a) (+ a b)) 2)
+(begin (set sequenced 1) (set sequenced (+ sequenced 1)))
+(assertEquals sequenced 2)
+(define sign (n)
+    (cond
+        (< n 0) (begin (set result (- 0 1)) result)
+        true (begin (set result 1) result)))
+(assertEquals (sign 5) 1)
+(assertEquals (sign (- 0 5)) (- 0 1))
+(define classify (n)
+    (cond
+        ((< n 0) (set classified 'negative) "negative")
+        ((= n 0) "zero")
+        (true (set classified 'positive) "positive")))
+(assertEquals (classify -3) "negative")
+(assertEquals classified 'negative)
+(assertEquals (classify 0) "zero")
+(assertEquals classified 'negative)
+(assertEquals (classify 4) "positive")
+(assertEquals classified 'positive)
+(define three 3)
+(assertEquals (cond (three 10)) 10)
+(assertEquals (cond (nil 1) (true 2)) 2)
+(assertEquals (cond (nil 1) (three "three" 3)) 3)
+
+""
+"Test native functions as values"
+(define foldLeft (f acc lst)
+    (cond
+        (nil? lst) acc
+        true (foldLeft f (f acc (car lst)) (cdr lst))))
+(assertEquals (foldLeft + 0 '(1 2 3 4)) 10)
+(assertEquals (foldLeft * 1 (list 1 2 3 4)) 24)
+(define mapList (f lst)
+    (cond
+        (nil? lst) nil
+        true (cons (f (car lst)) (mapList f (cdr lst)))))
+(assertEquals (car (mapList car '((1 2) (3 4)))) 1)
+(assertEquals (car (cdr (mapList number? '(1 a)))) nil)
+(assertEquals (car (mapList symbol? '(a 1))) true)
+(assertEquals (car (cdr (mapList symbol? '(a 1)))) nil)
+(set less <)
+(assertEquals (less 1 2) true)
+(assertEquals (not? nil) true)
+car
++
+
+""
+"Test case insensitive symbols with case preserving strings"
+(SET MixedCase "Hello World")
+(assertEquals mixedcase "Hello World")
+(assertEquals (= "Hello" "hello") nil)
+(DEFINE Shout (s) (+ s "!"))
+(shout MixedCase)
+
+""
+"Test escape sequences and unicode"
+"Tab:\tNewline:\nQuote: \" Backslash: \\ Smiley: \u{1F600}"
+(assertEquals "\u{e9}" "é")
+(set größe 5)
+(assertEquals (+ größe 1) 6)
+'(λ ünïcödé)
+
+""
+"Test integers, bignums and exact rationals"
+(assertEquals (factorial 25) 15511210043330985984000000)
+(factorial 25)
+(assertEquals (+ 9223372036854775807 1) 9223372036854775808)
+(assertEquals (- (+ 9223372036854775807 1) 1) 9223372036854775807)
+(/ 1 3)
+(assertEquals (/ 6 3) 2)
+(assertEquals (+ 1/3 2/3) 1)
+(assertEquals (= (+ 1/10 2/10) 3/10) true)
+(assertEquals (* 1.5 2) 3)
+(assertEquals (= 1 1.0) true)
+(assertEquals (< 1/3 0.34) true)
+(assertEquals (number? 2/3) true)
+
+""
+"Test variadic operators"
+(assertEquals (+ 1 2 3 4) 10)
+(assertEquals (+) 0)
+(assertEquals (* 2 3 4) 24)
+(assertEquals (- 10 1 2) 7)
+(assertEquals (- 5) (- 0 5))
+(assertEquals (/ 4) 1/4)
+(assertEquals (/ 12 2 3) 2)
+(assertEquals (+ "a" "b" "c") "abc")
+(assertEquals (< 1 2 3) true)
+(assertEquals (< 1 3 2) nil)
+(assertEquals (<= 1 1 2) true)
+(assertEquals (>= 3 3 4) nil)
+(assertEquals (= 2 2 2) true)
+(assertEquals (!= 1 2 3) true)
+(assertEquals (/= 1 2 1) nil)
+(set atMost <=)
+(assertEquals (atMost 1 2 2) true)
+
+""
+"Test signed, scientific and radix number literals"
+(set negative -5)
+(assertEquals negative (- 0 5))
+(assertEquals (+ 1 -1) 0)
+(assertEquals +7 7)
+(assertEquals (- 5) -5)
+(assertEquals -1/2 (/ -1 2))
+(assertEquals 1e3 1000)
+(assertEquals 2.5E-1 1/4)
+(assertEquals #xFF 255)
+(assertEquals #b-101 -5)
+(assertEquals #o17 15)
+(assertEquals #x10000000000000000 (* 4294967296 4294967296))
+
+""
+"Test comments" ; a line comment
+#| a block comment
+   #| which nests |#
+   (car 1) |#
+(assertEquals (car (cdr (list 1 #;(2 3) 4))) 4)
+(assertEquals (+ 1 #| inline |# 2) 3)
+(assertEquals (car (list #; #; 1 2 3)) 3)
+#;(car 1)
+
+""
+"Test closures sharing the bindings they capture"
+(define makeCounter ()
+    (let ((count 0))
+        (lambda () (set! count (+ count 1)) count)))
+(set counter (makeCounter))
+(counter)
+(assertEquals (counter) 2)
+(set otherCounter (makeCounter))
+(assertEquals (otherCounter) 1)
+(assertEquals (counter) 3)
+(define makeAccumulator (total)
+    (lambda (amount) (set! total (+ total amount)) total))
+(set accumulate (makeAccumulator 10))
+(accumulate 5)
+(assertEquals (accumulate 10) 25)
+(define makeAccount (balance)
+    (list (lambda (amount) (set! balance (+ balance amount)))
+          (lambda () balance)))
+(set account (makeAccount 100))
+((car account) 50)
+(assertEquals ((car (cdr account))) 150)
+(set shared 1)
+(define readShared () shared)
+(set! shared 2)
+(assertEquals (readShared) 2)
+
+""
+"Test define, set!, set, defvar and defparameter"
+(define answer 42)
+(assertEquals answer 42)
+(define (cube n) (* n n n))
+(assertEquals (cube 3) 27)
+(define localDefine ()
+    (define hidden 1)
+    (+ hidden 1))
+(assertEquals (localDefine) 2)
+(define shadowAnswer (answer) (define answer 0) answer)
+(assertEquals (shadowAnswer 1) 0)
+(assertEquals answer 42)
+(define setsGlobal () (set fromFunction 7))
+(setsGlobal)
+(assertEquals fromFunction 7)
+(define setsCaptured (n) (set n 8) n)
+(assertEquals (setsCaptured 1) 8)
+(define definesGlobals ()
+    (defvar limit 10)
+    (defparameter threshold 20))
+(definesGlobals)
+(assertEquals limit 10)
+(defvar limit 11)
+(assertEquals limit 10)
+(defparameter threshold 21)
+(assertEquals threshold 21)
+(set! answer 43)
+(assertEquals answer 43)
+
+""
+"Test raising and catching errors"
+(assertEquals (try (car 1) (catch (e) (error-message e))) "CAR operation must have a list as the first operand")
+(assertEquals (try (car 1) (catch (e) (error-kind e))) 'runtime-error)
+(assertEquals (try (error "bad input" 1 2) (catch (e) (error-message e))) "bad input")
+(assertEquals (try (error "bad input" 1 2) (catch (e) (car (cdr (error-data e))))) 2)
+(assertEquals (try (error 'not-found "missing key") (catch (e) (error-kind e))) 'not-found)
+(assertEquals (try (error "caught") (catch (e) (error? e))) true)
+(assertEquals (error? "not an error") nil)
+(assertEquals (try (+ 1 2) (catch (e) 0)) 3)
+(set cleanedUp nil)
+(assertEquals (try (+ 1 2) (finally (set cleanedUp true))) 3)
+(assertEquals cleanedUp true)
+(set cleanedUp nil)
+(assertEquals (try (try (error "inner") (finally (set cleanedUp true))) (catch (e) (error-message e))) "inner")
+(assertEquals cleanedUp true)
+(assertEquals (try (try (error 'rethrown "again") (catch (e) (error e))) (catch (e) (error-kind e))) 'rethrown)
+(define failsDeep (n) (cond (= n 0) (error "bottom" n) true (failsDeep (- n 1))))
+(assertEquals (try (failsDeep 50) (catch (e) (error-message e))) "bottom")
+(assertEquals (try ((1 2) 3) (catch (e) "caught")) "caught")
+(assertEquals (try (list (finally 1)) (catch (e) (error-kind e))) 'runtime-error)
+(define runaway (n) (+ 1 (runaway n)))
+(assertEquals (try (runaway 1) (catch (e) (error-message e))) "Stack overflow: more than 10000 nested calls.")
+
+""
+"Test conditions, handlers and restarts"
+(define getField (record name)
+    (restart-case
+        (cond
+            (nil? record) (error 'missing-field "missing field" name)
+            true (car record))
+        (use-value (v) v)
+        (skip () 'skipped)))
+(assertEquals (getField '(7) 'age) 7)
+(assertEquals (handler-bind ((missing-field (lambda (c) (invoke-restart 'use-value 42)))) (getField nil 'age)) 42)
+(assertEquals (handler-bind ((error (lambda (c) (invoke-restart 'skip)))) (getField nil 'age)) 'skipped)
+(assertEquals (handler-bind ((condition (lambda (c) (invoke-restart 'use-value (car (condition-data c)))))) (getField nil 'age)) 'age)
+(set noticed nil)
+(assertEquals (handler-bind ((note (lambda (c) (set noticed (condition-message c))))) (signal 'note "noticed") "continued") "continued")
+(assertEquals noticed "noticed")
+(set noticed nil)
+(handler-bind ((note (lambda (c) (set noticed 'outer))))
+    (handler-bind ((note (lambda (c) nil)))
+        (signal 'note "declined by the inner handler")))
+(assertEquals noticed 'outer)
+(assertEquals (restart-case (handler-bind ((note (lambda (c) (invoke-restart 'retry 1 2)))) (signal 'note "retry") 0) (retry (a b) (+ a b))) 3)
+(assertEquals (signal 'unhandled "nobody listens") nil)
+(assertEquals (car (restart-case (compute-restarts) (first () 1) (second () 2))) 'first)
+(assertEquals (try (handler-bind ((missing-field (lambda (c) nil))) (getField nil 'age)) (catch (e) (error-kind e))) 'missing-field)
+(assertEquals (condition? (make-condition 'note "made")) true)
+(assertEquals (condition-kind (make-condition "no kind")) 'condition)
+(set seen nil)
+(assertEquals (try (handler-bind ((error (lambda (c) (set seen (condition-kind c))))) (car 1)) (catch (e) 'caught)) 'caught)
+(assertEquals seen 'runtime-error)
+(assertEquals (restart-case (handler-bind ((error (lambda (c) (invoke-restart 'r 5)))) (car 1)) (r (x) x)) 5)
+(assertEquals (restart-case (handler-bind ((runtime-error (lambda (c) (invoke-restart 'r (condition-message c))))) undefinedName) (r (x) x)) "Undefined variable 'undefinedname'.")
+(assertEquals (restart-case (try (car 1) (catch (e) "caught")) (r () 1)) "caught")
+(set signals 0)
+(try (handler-bind ((error (lambda (c) (set signals (+ signals 1))))) (list (list (car 1)))) (catch (e) nil))
+(assertEquals signals 1)
+
+""
+"Test continuations and dynamic-wind"
+(define findFirst (pred lst)
+    (call/cc (lambda (return)
+        (define loop (l)
+            (cond
+                (nil? l) nil
+                (pred (car l)) (return (car l))
+                true (loop (cdr l))))
+        (loop lst))))
+(assertEquals (findFirst (lambda (x) (> x 2)) '(1 2 3 4)) 3)
+(assertEquals (findFirst (lambda (x) (> x 9)) '(1 2 3 4)) nil)
+(assertEquals (call-with-current-continuation (lambda (k) (+ 1 (k 41)))) 41)
+(assertEquals (call/cc (lambda (k) 5)) 5)
+(assertEquals (call/cc (lambda (outer) (call/cc (lambda (inner) (outer 'outer))) 'inner)) 'outer)
+(set wound nil)
+(assertEquals (call/cc (lambda (k) (dynamic-wind (lambda () (set wound (cons 'before wound))) (lambda () (k 'escaped)) (lambda () (set wound (cons 'after wound)))))) 'escaped)
+(assertEquals (car wound) 'after)
+(assertEquals (car (cdr wound)) 'before)
+(assertEquals (try (dynamic-wind (lambda () nil) (lambda () (error "boom")) (lambda () (set wound 'cleaned))) (catch (e) (error-message e))) "boom")
+(assertEquals wound 'cleaned)
+(set saved nil)
+(call/cc (lambda (k) (set saved k)))
+(assertEquals (try (saved 1) (catch (e) (error-kind e))) 'runtime-error)
+
+""
+"Test macros"
+(defmacro when (test &rest body) `(cond ,test (begin ,@body) true nil))
+(defmacro unless (test &rest body) `(cond ,test nil true (begin ,@body)))
+(defmacro while (test &rest body)
+    `(letrec ((loop (lambda () (cond ,test (begin ,@body (loop)) true nil)))) (loop)))
+(assertEquals (when (> 3 2) 'first 'second) 'second)
+(assertEquals (when (< 3 2) 'first) nil)
+(assertEquals (unless (< 3 2) 'yes) 'yes)
+(define count 0)
+(define total 0)
+(while (< count 5) (set! total (+ total count)) (set! count (+ count 1)))
+(assertEquals total 10)
+(defmacro swap! (a b) `(let ((tmp ,a)) (set! ,a ,b) (set! ,b tmp)))
+(define left 1)
+(define right 2)
+(swap! left right)
+(assertEquals left 2)
+(assertEquals right 1)
+(defmacro twice (form) `(begin ,form ,form))
+(twice (set! left (* left 10)))
+(assertEquals left 200)
+(defmacro always-when () '(when true "expanded again"))
+(assertEquals (always-when) "expanded again")
+(assertEquals (car (macroexpand-1 '(when a b c))) 'cond)
+(assertEquals (car (car (cdr (cdr (macroexpand-1 '(when a b c)))))) 'begin)
+(assertEquals (car (macroexpand '(always-when))) 'cond)
+(assertEquals (car (macroexpand-1 '(always-when))) 'when)
+(assertEquals (car (cdr (macroexpand '(+ 1 2)))) 1)
+(define quoteForm (form) (list 'quote form))
+(defmacro quoted (form) (quoteForm form))
+(assertEquals (quoted hello) 'hello)
+(defmacro notSymbol () (symbol? 5))
+(assertEquals (nil? (notSymbol)) true)